@@ -0,0 +1,264 @@
+package botpolicy
+
+import (
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gianluca-marchini/devicedetector"
+	"github.com/gianluca-marchini/devicedetector/botverify"
+	"github.com/gianluca-marchini/devicedetector/parser"
+)
+
+// Number of served requests per outcome, and per rule name
+type Counters struct {
+	Allowed    uint64
+	Denied     uint64
+	Challenged uint64
+	// Requests rejected by a rate limit
+	Limited uint64
+	Rules   map[string]uint64
+}
+
+type windowKey struct {
+	rule    *Rule
+	bot, ip string
+}
+
+type window struct {
+	start time.Time
+	count int
+}
+
+type verifyKey struct {
+	bot, ip string
+}
+
+type verification struct {
+	status  botverify.Status
+	expires time.Time
+}
+
+// Entries kept by the rate limit windows and the verification cache, the
+// oldest are dropped beyond
+const maxEntries = 10000
+
+// Entries dropped at once when full and none is over, so that the next
+// insertions don't scan the entries again
+const evictBatch = maxEntries / 10
+
+// How long a verification result is reused for the same bot and client
+const DefaultVerifyTTL = time.Hour
+
+// Middleware applying a policy to the requests of the detected bots, the
+// other requests are passed through
+type Handler struct {
+	next     http.Handler
+	detector *devicedetector.DeviceDetector
+	policy   *Policy
+
+	// Verifies the bots for the rules on verification. Without it, the
+	// bots are unverifiable.
+	Verifier *botverify.Verifier
+	// Serves the challenge decisions instead of the challenge response
+	Challenge http.Handler
+	// IP of the client, the host of RemoteAddr when nil
+	ClientIP func(*http.Request) string
+	// How long the verification results are cached, 0 disables the cache
+	VerifyTTL time.Duration
+
+	mu       sync.Mutex
+	windows  map[windowKey]*window
+	verified map[verifyKey]verification
+	counters Counters
+	now      func() time.Time
+}
+
+func NewHandler(next http.Handler, detector *devicedetector.DeviceDetector, policy *Policy) *Handler {
+	return &Handler{
+		next:      next,
+		detector:  detector,
+		policy:    policy,
+		VerifyTTL: DefaultVerifyTTL,
+		windows:   make(map[windowKey]*window),
+		verified:  make(map[verifyKey]verification),
+		counters:  Counters{Rules: make(map[string]uint64)},
+		now:       time.Now,
+	}
+}
+
+// Snapshot of the counters
+func (h *Handler) Counters() Counters {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	c := h.counters
+	c.Rules = make(map[string]uint64, len(h.counters.Rules))
+	for k, v := range h.counters.Rules {
+		c.Rules[k] = v
+	}
+	return c
+}
+
+func (h *Handler) clientIP(r *http.Request) string {
+	if h.ClientIP != nil {
+		return h.ClientIP(r)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	info := h.detector.Parse(r.UserAgent())
+	if info == nil || !info.IsBot() {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	bot := info.GetBot()
+	ip := h.clientIP(r)
+
+	status := botverify.StatusUnverifiable
+	if h.Verifier != nil && h.policy.needsVerification() {
+		status = h.verify(r, bot, ip)
+	}
+	decision := h.policy.Evaluate(bot, status)
+	action := decision.Action
+	if action == ActionRateLimit {
+		if h.allow(decision.Rule, bot.Name, ip) {
+			action = ActionAllow
+		}
+	}
+	h.count(decision, action)
+
+	switch action {
+	case ActionAllow:
+		h.next.ServeHTTP(w, r)
+	case ActionChallenge:
+		if h.Challenge != nil {
+			h.Challenge.ServeHTTP(w, r)
+			return
+		}
+		h.respond(w, action)
+	case ActionRateLimit:
+		w.Header().Set("Retry-After", strconv.Itoa(int(decision.Rule.Window.Seconds())))
+		h.respond(w, action)
+	default:
+		h.respond(w, action)
+	}
+}
+
+func (h *Handler) respond(w http.ResponseWriter, a Action) {
+	resp := h.policy.response(a)
+	for k, v := range resp.Headers {
+		w.Header().Set(k, v)
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.WriteHeader(resp.Status)
+	w.Write([]byte(resp.Body))
+}
+
+func (h *Handler) count(d Decision, action Action) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch action {
+	case ActionAllow:
+		h.counters.Allowed++
+	case ActionDeny:
+		h.counters.Denied++
+	case ActionChallenge:
+		h.counters.Challenged++
+	case ActionRateLimit:
+		h.counters.Limited++
+	}
+	if d.Rule != nil && d.Rule.Name != "" {
+		h.counters.Rules[d.Rule.Name]++
+	}
+}
+
+// Verification status of the bot for ip, reusing the results of the last
+// VerifyTTL. The DNS failures aren't cached.
+func (h *Handler) verify(r *http.Request, bot *parser.BotMatchResult, ip string) botverify.Status {
+	key := verifyKey{bot.Name, ip}
+	if h.VerifyTTL > 0 {
+		h.mu.Lock()
+		v, ok := h.verified[key]
+		h.mu.Unlock()
+		if ok && h.now().Before(v.expires) {
+			return v.status
+		}
+	}
+	res := h.Verifier.Verify(r.Context(), bot, ip)
+	if h.VerifyTTL > 0 && res.Err == nil {
+		h.mu.Lock()
+		now := h.now()
+		if _, ok := h.verified[key]; !ok && len(h.verified) >= maxEntries {
+			h.purgeVerified(now)
+		}
+		h.verified[key] = verification{res.Status, now.Add(h.VerifyTTL)}
+		h.mu.Unlock()
+	}
+	return res.Status
+}
+
+// Drop the expired verifications, and the oldest ones when none is
+func (h *Handler) purgeVerified(now time.Time) {
+	for k, v := range h.verified {
+		if !now.Before(v.expires) {
+			delete(h.verified, k)
+		}
+	}
+	if len(h.verified) >= maxEntries {
+		evictOldest(h.verified, func(v verification) time.Time { return v.expires })
+	}
+}
+
+// Drop the evictBatch entries of m with the earliest time
+func evictOldest[K comparable, V any](m map[K]V, at func(V) time.Time) {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return at(m[keys[i]]).Before(at(m[keys[j]]))
+	})
+	for _, k := range keys[:min(evictBatch, len(keys))] {
+		delete(m, k)
+	}
+}
+
+// Fixed window rate limit per rule, bot and client
+func (h *Handler) allow(rule *Rule, bot, ip string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.now()
+	key := windowKey{rule, bot, ip}
+	win, ok := h.windows[key]
+	if !ok || now.Sub(win.start) >= rule.Window {
+		if !ok && len(h.windows) >= maxEntries {
+			h.purge(now)
+		}
+		win = &window{start: now}
+		h.windows[key] = win
+	}
+	win.count++
+	return win.count <= rule.Limit
+}
+
+// Drop the windows that are over, and the oldest ones when none is
+func (h *Handler) purge(now time.Time) {
+	for k, win := range h.windows {
+		if now.Sub(win.start) >= k.rule.Window {
+			delete(h.windows, k)
+		}
+	}
+	if len(h.windows) >= maxEntries {
+		evictOldest(h.windows, func(win *window) time.Time { return win.start })
+	}
+}
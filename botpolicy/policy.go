@@ -0,0 +1,180 @@
+// Package botpolicy decides how the requests of the detected bots are served
+// (allowed, denied, challenged or rate limited) from declarative rules on the
+// bot category, name, operator and verification status, and enforces the
+// decisions in an http.Handler.
+package botpolicy
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gianluca-marchini/devicedetector/botverify"
+	"github.com/gianluca-marchini/devicedetector/parser"
+	"gopkg.in/yaml.v2"
+)
+
+type Action string
+
+const (
+	ActionAllow     Action = "allow"
+	ActionDeny      Action = "deny"
+	ActionChallenge Action = "challenge"
+	ActionRateLimit Action = "rate-limit"
+)
+
+// A policy rule. Each criterion lists alternatives compared case
+// insensitively, an empty criterion matches any bot.
+type Rule struct {
+	Name       string   `yaml:"name"`
+	Categories []string `yaml:"categories"`
+	Names      []string `yaml:"names"`
+	// Operators of the bot, compared to the producer name
+	Producers []string `yaml:"producers"`
+	// Outcomes of the bot verification, see botverify
+	Verification []botverify.Status `yaml:"verification"`
+	Action       Action             `yaml:"action"`
+	// Requests allowed per window and client for rate-limit rules
+	Limit  int           `yaml:"limit"`
+	Window time.Duration `yaml:"window"`
+}
+
+// Response sent for the deny, challenge and rate-limit decisions
+type Response struct {
+	Status  int               `yaml:"status"`
+	Body    string            `yaml:"body"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+var DefaultResponses = map[Action]*Response{
+	ActionDeny:      {Status: http.StatusForbidden, Body: "Forbidden\n"},
+	ActionChallenge: {Status: http.StatusForbidden, Body: "Challenge required\n"},
+	ActionRateLimit: {Status: http.StatusTooManyRequests, Body: "Too Many Requests\n"},
+}
+
+// Ordered rules, the first rule matching a bot decides
+type Policy struct {
+	Rules []*Rule `yaml:"rules"`
+	// Action for the bots no rule matches, allow when empty
+	Default Action `yaml:"default"`
+	// Responses overriding DefaultResponses
+	Responses map[Action]*Response `yaml:"responses"`
+}
+
+type Decision struct {
+	Action Action
+	// Matching rule, nil for the default action
+	Rule *Rule
+}
+
+// Read a policy from a YAML file
+func LoadPolicy(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	p := &Policy{}
+	if err := yaml.UnmarshalStrict(data, p); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return p, nil
+}
+
+func validAction(a Action) bool {
+	switch a {
+	case ActionAllow, ActionDeny, ActionChallenge, ActionRateLimit:
+		return true
+	}
+	return false
+}
+
+// Check the actions and the rate limits of the policy
+func (p *Policy) Validate() error {
+	if p.Default != "" && !validAction(p.Default) {
+		return fmt.Errorf("unknown default action %q", p.Default)
+	}
+	if p.Default == ActionRateLimit {
+		return fmt.Errorf("default action can't be %q", ActionRateLimit)
+	}
+	for i, r := range p.Rules {
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i)
+		}
+		if !validAction(r.Action) {
+			return fmt.Errorf("rule %s: unknown action %q", name, r.Action)
+		}
+		if r.Action == ActionRateLimit && (r.Limit <= 0 || r.Window <= 0) {
+			return fmt.Errorf("rule %s: rate limit needs a positive limit and window", name)
+		}
+	}
+	for a := range p.Responses {
+		if !validAction(a) {
+			return fmt.Errorf("response for unknown action %q", a)
+		}
+	}
+	return nil
+}
+
+func matchAny(values []string, v string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, value := range values {
+		if strings.EqualFold(value, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) Match(bot *parser.BotMatchResult, status botverify.Status) bool {
+	if !matchAny(r.Categories, bot.Category) || !matchAny(r.Names, bot.Name) ||
+		!matchAny(r.Producers, bot.Producer.Name) {
+		return false
+	}
+	if len(r.Verification) == 0 {
+		return true
+	}
+	for _, s := range r.Verification {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Decide how to serve bot, whose verification ended with status
+func (p *Policy) Evaluate(bot *parser.BotMatchResult, status botverify.Status) Decision {
+	for _, r := range p.Rules {
+		if r.Match(bot, status) {
+			return Decision{Action: r.Action, Rule: r}
+		}
+	}
+	if p.Default == "" {
+		return Decision{Action: ActionAllow}
+	}
+	return Decision{Action: p.Default}
+}
+
+// Whether some rules depend on the bot verification
+func (p *Policy) needsVerification() bool {
+	for _, r := range p.Rules {
+		if len(r.Verification) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) response(a Action) *Response {
+	if r, ok := p.Responses[a]; ok && r != nil {
+		return r
+	}
+	return DefaultResponses[a]
+}
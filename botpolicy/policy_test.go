@@ -0,0 +1,177 @@
+package botpolicy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gianluca-marchini/devicedetector"
+	"github.com/gianluca-marchini/devicedetector/botverify"
+	"github.com/gianluca-marchini/devicedetector/parser"
+	"github.com/stretchr/testify/require"
+)
+
+var dd, _ = devicedetector.NewDeviceDetector("../regexes", false)
+
+const (
+	googlebot = `Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)`
+	bingbot   = `Mozilla/5.0 (compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm)`
+	gptbot    = `Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; GPTBot/1.1; +https://openai.com/gptbot)`
+	gtmetrix  = `Mozilla/5.0 (X11; Linux x86_64; GTmetrix https://gtmetrix.com/) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/75.0.3770.100 Safari/537.36`
+	arachni   = `Arachni/v1.5.1`
+	chrome    = `Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36`
+)
+
+// resolves nothing, so the DNS checks fail
+type noResolver struct{}
+
+func (noResolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	return nil, &net.DNSError{Err: "no such host", Name: addr, IsNotFound: true}
+}
+
+func (noResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	p, err := LoadPolicy("fixtures/policy.yml")
+	require.NoError(t, err)
+	require.Len(t, p.Rules, 5)
+	require.Equal(t, time.Minute, p.Rules[3].Window)
+	require.Equal(t, "Access denied\n", p.response(ActionDeny).Body)
+	require.Equal(t, http.StatusTooManyRequests, p.response(ActionRateLimit).Status)
+
+	require.Error(t, (&Policy{Default: "block"}).Validate())
+	require.Error(t, (&Policy{Rules: []*Rule{{Action: ActionRateLimit}}}).Validate())
+}
+
+func TestEvaluate(t *testing.T) {
+	p, err := LoadPolicy("fixtures/policy.yml")
+	require.NoError(t, err)
+
+	google := dd.Parse(googlebot).GetBot()
+	require.Equal(t, "fake-googlebot", p.Evaluate(google, botverify.StatusSpoofed).Rule.Name)
+	require.Equal(t, ActionAllow, p.Evaluate(google, botverify.StatusVerified).Action)
+	require.Equal(t, "search", p.Evaluate(google, botverify.StatusUnverifiable).Rule.Name)
+	require.Equal(t, ActionChallenge, p.Evaluate(dd.Parse(gptbot).GetBot(), botverify.StatusUnverifiable).Action)
+	require.Equal(t, ActionDeny, p.Evaluate(dd.Parse(arachni).GetBot(), botverify.StatusUnverifiable).Action)
+
+	d := p.Evaluate(&parser.BotMatchResult{Name: "Foo", Category: "Feed Fetcher"}, botverify.StatusUnverifiable)
+	require.Equal(t, ActionAllow, d.Action)
+	require.Nil(t, d.Rule)
+
+	byProducer := &Policy{Rules: []*Rule{{Producers: []string{"openai"}, Action: ActionDeny}}}
+	require.Equal(t, ActionDeny, byProducer.Evaluate(dd.Parse(gptbot).GetBot(), "").Action)
+}
+
+func TestHandler(t *testing.T) {
+	p, err := LoadPolicy("fixtures/policy.yml")
+	require.NoError(t, err)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	h := NewHandler(next, dd, p)
+	h.Verifier = botverify.NewVerifier("../botverify/fixtures", noResolver{})
+
+	serve := func(ua, ip string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("User-Agent", ua)
+		r.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, serve(chrome, "192.0.2.1").Code)
+	require.Equal(t, http.StatusOK, serve(googlebot, "66.249.64.1").Code)
+	w := serve(googlebot, "192.0.2.1")
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Equal(t, "Access denied\n", w.Body.String())
+	require.Equal(t, "deny", w.Header().Get("X-Bot-Policy"))
+	require.Equal(t, http.StatusOK, serve(bingbot, "192.0.2.1").Code)
+	require.Equal(t, http.StatusForbidden, serve(arachni, "192.0.2.1").Code)
+	require.Equal(t, http.StatusForbidden, serve(gptbot, "192.0.2.1").Code)
+
+	h.Challenge = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	require.Equal(t, http.StatusUnauthorized, serve(gptbot, "192.0.2.1").Code)
+
+	// 2 requests per minute and client
+	now := time.Now()
+	h.now = func() time.Time { return now }
+	require.Equal(t, http.StatusOK, serve(gtmetrix, "192.0.2.1").Code)
+	require.Equal(t, http.StatusOK, serve(gtmetrix, "192.0.2.1").Code)
+	w = serve(gtmetrix, "192.0.2.1")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "60", w.Header().Get("Retry-After"))
+	require.Equal(t, http.StatusOK, serve(gtmetrix, "192.0.2.2").Code)
+	now = now.Add(time.Minute)
+	require.Equal(t, http.StatusOK, serve(gtmetrix, "192.0.2.1").Code)
+
+	c := h.Counters()
+	require.Equal(t, uint64(6), c.Allowed)
+	require.Equal(t, uint64(2), c.Denied)
+	require.Equal(t, uint64(2), c.Challenged)
+	require.Equal(t, uint64(1), c.Limited)
+	require.Equal(t, uint64(5), c.Rules["crawlers"])
+	require.Equal(t, uint64(1), c.Rules["fake-googlebot"])
+}
+
+// counts the reverse lookups
+type countingResolver struct {
+	noResolver
+	lookups int
+}
+
+func (r *countingResolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	r.lookups++
+	return r.noResolver.LookupAddr(ctx, addr)
+}
+
+func TestHandlerCaches(t *testing.T) {
+	p, err := LoadPolicy("fixtures/policy.yml")
+	require.NoError(t, err)
+	h := NewHandler(http.NotFoundHandler(), dd, p)
+	resolver := &countingResolver{}
+	h.Verifier = botverify.NewVerifier("../botverify/fixtures", resolver)
+	now := time.Now()
+	h.now = func() time.Time { return now }
+
+	serve := func(ua, ip string) int {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("User-Agent", ua)
+		r.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// the verification is reused until it expires
+	require.Equal(t, http.StatusForbidden, serve(googlebot, "192.0.2.1"))
+	require.Equal(t, http.StatusForbidden, serve(googlebot, "192.0.2.1"))
+	require.Equal(t, 1, resolver.lookups)
+	serve(googlebot, "192.0.2.2")
+	require.Equal(t, 2, resolver.lookups)
+	now = now.Add(DefaultVerifyTTL)
+	serve(googlebot, "192.0.2.1")
+	require.Equal(t, 3, resolver.lookups)
+
+	// the windows still running are dropped from the oldest when full
+	rule := p.Rules[3]
+	for i := 0; i < maxEntries; i++ {
+		h.windows[windowKey{rule, "GTmetrix", strconv.Itoa(i)}] = &window{start: now.Add(time.Duration(i))}
+	}
+	require.True(t, h.allow(rule, "GTmetrix", "192.0.2.1"))
+	require.Len(t, h.windows, maxEntries-evictBatch+1)
+	require.NotContains(t, h.windows, windowKey{rule, "GTmetrix", "0"})
+	require.NotContains(t, h.windows, windowKey{rule, "GTmetrix", strconv.Itoa(evictBatch - 1)})
+	require.Contains(t, h.windows, windowKey{rule, "GTmetrix", strconv.Itoa(evictBatch)})
+	// the next insertions don't purge
+	require.True(t, h.allow(rule, "GTmetrix", "192.0.2.2"))
+	require.Len(t, h.windows, maxEntries-evictBatch+2)
+}
@@ -0,0 +1,227 @@
+// Package botverify checks that the requests detected as coming from a bot
+// really originate from its operator, using the published IP ranges of the
+// bot and forward-confirmed reverse DNS.
+package botverify
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gianluca-marchini/devicedetector"
+	"github.com/gianluca-marchini/devicedetector/parser"
+)
+
+type Status string
+
+const (
+	// The request comes from the bot operator
+	StatusVerified Status = "verified"
+	// The request claims to come from a bot but its origin doesn't match
+	StatusSpoofed Status = "spoofed"
+	// Not enough information to decide: not a bot, no verification data
+	// published for the bot, invalid IP or DNS failure
+	StatusUnverifiable Status = "unverifiable"
+)
+
+const (
+	MethodIPRange = "ip range"
+	MethodDNS     = "reverse dns"
+)
+
+type Result struct {
+	Status Status
+	// Method used to verify the bot, when verified
+	Method string
+	// Hostname the IP resolved to, when verified through DNS
+	Hostname string
+	// Error preventing the verification, when unverifiable
+	Err error
+}
+
+// DNS resolution used for the reverse DNS checks, implemented by *net.Resolver
+type Resolver interface {
+	LookupAddr(ctx context.Context, addr string) ([]string, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+type Verifier struct {
+	dir      string
+	resolver Resolver
+
+	mu     sync.Mutex
+	ranges map[string][]*net.IPNet
+}
+
+// Create a verifier.
+// - dir: folder containing the IP ranges files referenced by bots.yml. A
+// missing file only disables the IP range check for the bots referencing it.
+// - resolver: used for the reverse DNS checks, net.DefaultResolver when nil
+func NewVerifier(dir string, resolver Resolver) *Verifier {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &Verifier{
+		dir:      dir,
+		resolver: resolver,
+		ranges:   make(map[string][]*net.IPNet),
+	}
+}
+
+// Check that the bot detected in info is really operating from remoteIP
+func (v *Verifier) VerifyBot(info *devicedetector.DeviceInfo, remoteIP string) Result {
+	return v.VerifyBotContext(context.Background(), info, remoteIP)
+}
+
+func (v *Verifier) VerifyBotContext(ctx context.Context, info *devicedetector.DeviceInfo, remoteIP string) Result {
+	if info == nil || !info.IsBot() {
+		return Result{Status: StatusUnverifiable}
+	}
+	return v.Verify(ctx, info.GetBot(), remoteIP)
+}
+
+// Check that bot is really operating from remoteIP
+func (v *Verifier) Verify(ctx context.Context, bot *parser.BotMatchResult, remoteIP string) Result {
+	if bot == nil || bot.Verification == nil {
+		return Result{Status: StatusUnverifiable}
+	}
+	ip := net.ParseIP(strings.TrimSpace(remoteIP))
+	if ip == nil {
+		return Result{Status: StatusUnverifiable, Err: fmt.Errorf("invalid ip %q", remoteIP)}
+	}
+
+	checked := false
+	for _, file := range bot.Verification.IPRanges {
+		ranges, found, err := v.loadRanges(file)
+		if err != nil {
+			return Result{Status: StatusUnverifiable, Err: err}
+		}
+		if !found {
+			continue
+		}
+		checked = true
+		for _, r := range ranges {
+			if r.Contains(ip) {
+				return Result{Status: StatusVerified, Method: MethodIPRange}
+			}
+		}
+	}
+
+	if len(bot.Verification.Hostnames) > 0 {
+		hostname, err := v.reverseDNS(ctx, ip, bot.Verification.Hostnames)
+		if err != nil {
+			return Result{Status: StatusUnverifiable, Err: err}
+		}
+		if hostname != "" {
+			return Result{Status: StatusVerified, Method: MethodDNS, Hostname: hostname}
+		}
+		checked = true
+	}
+
+	if !checked {
+		return Result{Status: StatusUnverifiable}
+	}
+	return Result{Status: StatusSpoofed}
+}
+
+// Returns the hostname ip resolves to when it belongs to one of the domains
+// and resolves back to ip, or an empty string
+func (v *Verifier) reverseDNS(ctx context.Context, ip net.IP, domains []string) (string, error) {
+	names, err := v.resolver.LookupAddr(ctx, ip.String())
+	if err != nil {
+		if isNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	for _, name := range names {
+		name = strings.TrimSuffix(strings.ToLower(name), ".")
+		if !matchDomain(name, domains) {
+			continue
+		}
+		addrs, err := v.resolver.LookupHost(ctx, name)
+		if err != nil {
+			if isNotFound(err) {
+				continue
+			}
+			return "", err
+		}
+		for _, addr := range addrs {
+			if resolved := net.ParseIP(addr); resolved != nil && resolved.Equal(ip) {
+				return name, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+func matchDomain(name string, domains []string) bool {
+	for _, domain := range domains {
+		domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+		if name == domain || strings.HasSuffix(name, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *Verifier) loadRanges(file string) ([]*net.IPNet, bool, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if ranges, ok := v.ranges[file]; ok {
+		return ranges, ranges != nil, nil
+	}
+	ranges, err := ReadIPRanges(filepath.Join(v.dir, file))
+	if os.IsNotExist(err) {
+		v.ranges[file] = nil
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	v.ranges[file] = ranges
+	return ranges, true, nil
+}
+
+// Read a file listing IP ranges in CIDR notation, one per line. Single IPs
+// are accepted, empty lines and lines starting with # are ignored.
+func ReadIPRanges(file string) ([]*net.IPNet, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ranges := make([]*net.IPNet, 0)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if !strings.Contains(text, "/") {
+			if ip := net.ParseIP(text); ip != nil && ip.To4() != nil {
+				text += "/32"
+			} else {
+				text += "/128"
+			}
+		}
+		_, r, err := net.ParseCIDR(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file, line, err)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, scanner.Err()
+}
@@ -0,0 +1,108 @@
+package botverify
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/gianluca-marchini/devicedetector"
+	"github.com/stretchr/testify/require"
+)
+
+var dd, _ = devicedetector.NewDeviceDetector("../regexes", false)
+
+const googlebot = `Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)`
+
+type fakeResolver struct {
+	addrs map[string][]string
+	hosts map[string][]string
+	err   error
+}
+
+func (f *fakeResolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if names, ok := f.addrs[addr]; ok {
+		return names, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: addr, IsNotFound: true}
+}
+
+func (f *fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if addrs, ok := f.hosts[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestVerifyBot(t *testing.T) {
+	resolver := &fakeResolver{
+		addrs: map[string][]string{
+			`66.249.66.1`:  {`crawl-66-249-66-1.googlebot.com.`},
+			`203.0.113.10`: {`crawl-66-249-66-1.googlebot.com.`},
+			`203.0.113.11`: {`googlebot.com.evil.example.`},
+		},
+		hosts: map[string][]string{
+			`crawl-66-249-66-1.googlebot.com`: {`66.249.66.1`},
+			`googlebot.com.evil.example`:      {`203.0.113.11`},
+		},
+	}
+	v := NewVerifier(`fixtures`, resolver)
+	info := dd.Parse(googlebot)
+
+	r := v.VerifyBot(info, `66.249.64.12`)
+	require.Equal(t, StatusVerified, r.Status)
+	require.Equal(t, MethodIPRange, r.Method)
+
+	r = v.VerifyBot(info, `2001:4860:4801:10::1`)
+	require.Equal(t, StatusVerified, r.Status)
+
+	r = v.VerifyBot(info, `66.249.66.1`)
+	require.Equal(t, StatusVerified, r.Status)
+	require.Equal(t, MethodDNS, r.Method)
+	require.Equal(t, `crawl-66-249-66-1.googlebot.com`, r.Hostname)
+
+	// the reverse name doesn't resolve back to the IP
+	require.Equal(t, StatusSpoofed, v.VerifyBot(info, `203.0.113.10`).Status)
+	// the reverse name isn't in the bot domains
+	require.Equal(t, StatusSpoofed, v.VerifyBot(info, `203.0.113.11`).Status)
+	// no reverse name
+	require.Equal(t, StatusSpoofed, v.VerifyBot(info, `203.0.113.12`).Status)
+}
+
+func TestVerifyBotUnverifiable(t *testing.T) {
+	v := NewVerifier(`fixtures`, &fakeResolver{err: errors.New("timeout")})
+
+	r := v.VerifyBot(dd.Parse(googlebot), `203.0.113.12`)
+	require.Equal(t, StatusUnverifiable, r.Status)
+	require.Error(t, r.Err)
+
+	r = v.VerifyBot(dd.Parse(googlebot), `not an ip`)
+	require.Equal(t, StatusUnverifiable, r.Status)
+	require.Error(t, r.Err)
+
+	// not a bot
+	r = v.VerifyBot(dd.Parse(`Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:78.0) Gecko/20100101 Firefox/78.0`), `203.0.113.12`)
+	require.Equal(t, StatusUnverifiable, r.Status)
+
+	// no verification information published
+	r = v.VerifyBot(dd.Parse(`Mozilla/5.0 (compatible; AhrefsBot/6.1; +http://ahrefs.com/robot/)`), `203.0.113.12`)
+	require.Equal(t, StatusUnverifiable, r.Status)
+
+	// the IP ranges file of the bot isn't available
+	r = v.VerifyBot(dd.Parse(`DuckDuckBot/1.0; (+http://duckduckgo.com/duckduckbot.html)`), `203.0.113.12`)
+	require.Equal(t, StatusUnverifiable, r.Status)
+	require.NoError(t, r.Err)
+}
+
+func TestReadIPRanges(t *testing.T) {
+	ranges, err := ReadIPRanges(`fixtures/googlebot.txt`)
+	require.NoError(t, err)
+	require.Len(t, ranges, 2)
+	require.Equal(t, `66.249.64.0/27`, ranges[0].String())
+
+	_, err = ReadIPRanges(`fixtures/missing.txt`)
+	require.Error(t, err)
+}
@@ -0,0 +1,222 @@
+package devicedetector
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	gover "github.com/mcuadros/go-version"
+
+	"github.com/gianluca-marchini/devicedetector/parser"
+	"github.com/gianluca-marchini/devicedetector/parser/client"
+)
+
+// Anomaly codes
+const (
+	AnomalyMissingUserAgent       = "missing-user-agent"
+	AnomalyClientHintsUnsupported = "client-hints-unsupported"
+	AnomalyClientHintsVersion     = "client-hints-version"
+	AnomalyClientHintsPlatform    = "client-hints-platform"
+	AnomalyClientHintsMobile      = "client-hints-mobile"
+	AnomalyUnsupportedOs          = "unsupported-os"
+	AnomalyMissingAccept          = "missing-accept"
+	AnomalyAcceptMismatch         = "accept-mismatch"
+	AnomalyBrandOs                = "brand-os"
+)
+
+// Points added to the score by each anomaly
+var anomalyWeights = map[string]int{
+	AnomalyMissingUserAgent:       30,
+	AnomalyClientHintsUnsupported: 40,
+	AnomalyClientHintsVersion:     40,
+	AnomalyClientHintsPlatform:    50,
+	AnomalyClientHintsMobile:      30,
+	AnomalyUnsupportedOs:          40,
+	AnomalyMissingAccept:          20,
+	AnomalyAcceptMismatch:         30,
+	AnomalyBrandOs:                50,
+}
+
+type Anomaly struct {
+	Code   string
+	Reason string
+}
+
+type ConsistencyReport struct {
+	Info      *DeviceInfo
+	Anomalies []Anomaly
+	// 0 when the signals agree, up to 100
+	Score int
+}
+
+func (r *ConsistencyReport) add(code, format string, args ...interface{}) {
+	r.Anomalies = append(r.Anomalies, Anomaly{Code: code, Reason: fmt.Sprintf(format, args...)})
+	r.Score += anomalyWeights[code]
+	if r.Score > 100 {
+		r.Score = 100
+	}
+}
+
+// Platforms of Sec-CH-UA-Platform mapped to os families
+var hintPlatforms = map[string]string{
+	`Android`:     `Android`,
+	`Chrome OS`:   `Chrome OS`,
+	`Chromium OS`: `Chrome OS`,
+	`iOS`:         `iOS`,
+	`Linux`:       `GNU/Linux`,
+	`macOS`:       `Mac`,
+	`Windows`:     `Windows`,
+}
+
+// Oldest os versions supported by the recent browser versions
+var minOsVersions = []struct {
+	browsers  []string
+	fromMajor string
+	os        string
+	minOs     string
+}{
+	{[]string{`CH`, `CM`}, `96`, `AND`, `6.0`},
+	{[]string{`CH`, `CM`}, `107`, `AND`, `7.0`},
+	{[]string{`CH`, `CM`}, `120`, `AND`, `8.0`},
+	{[]string{`CH`}, `110`, `WIN`, `10`},
+	{[]string{`CH`}, `117`, `MAC`, `10.15`},
+	{[]string{`FF`}, `116`, `WIN`, `10`},
+}
+
+var appleOsFamilies = []string{`iOS`, `Mac`, `Apple TV`}
+
+// Parse the user agent of req and look for signals disagreeing with it in
+// the client hints and the other headers
+func (d *DeviceDetector) AnalyzeConsistency(req *http.Request) *ConsistencyReport {
+	report := &ConsistencyReport{}
+	info := d.Parse(req.UserAgent())
+	if info == nil {
+		report.add(AnomalyMissingUserAgent, "no usable user agent")
+		return report
+	}
+	report.Info = info
+	// bots make no attempt at looking like a browser
+	if info.IsBot() {
+		return report
+	}
+	checkClientHints(report, info, req.Header)
+	checkOsVersion(report, info)
+	checkAccept(report, info, req.Header)
+	checkBrandOs(report, info)
+	return report
+}
+
+func majorVersion(v string) string {
+	major, _, _ := strings.Cut(v, ".")
+	return major
+}
+
+// Major version of the Chrome/ product token of ua
+func chromiumMajor(ua string) string {
+	_, after, ok := strings.Cut(ua, "Chrome/")
+	if !ok {
+		return ""
+	}
+	i := 0
+	for i < len(after) && after[i] >= '0' && after[i] <= '9' {
+		i++
+	}
+	return after[:i]
+}
+
+// Brands and versions of a Sec-CH-UA header: "Chromium";v="120", ...
+func parseBrands(header string) map[string]string {
+	brands := make(map[string]string)
+	for _, item := range strings.Split(header, ",") {
+		brand, params, _ := strings.Cut(strings.TrimSpace(item), ";")
+		version := ""
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "v="); ok {
+			version = strings.Trim(v, `"`)
+		}
+		brands[strings.Trim(brand, `"`)] = version
+	}
+	return brands
+}
+
+func checkClientHints(report *ConsistencyReport, info *DeviceInfo, header http.Header) {
+	hints := header.Get("Sec-CH-UA")
+	platform := strings.Trim(header.Get("Sec-CH-UA-Platform"), `"`)
+	mobile := header.Get("Sec-CH-UA-Mobile")
+	if hints == "" && platform == "" && mobile == "" {
+		return
+	}
+	c := info.GetClient()
+	if c.Type == client.ParserNameBrowser && c.Engine != "" && c.Engine != "Blink" {
+		report.add(AnomalyClientHintsUnsupported, "%s (%s) sends client hints, only Blink browsers do", c.Name, c.Engine)
+		return
+	}
+	if major := chromiumMajor(info.userAgent); hints != "" && major != "" {
+		if v, ok := parseBrands(hints)["Chromium"]; ok && v != "" && majorVersion(v) != major {
+			report.add(AnomalyClientHintsVersion, "client hints say Chromium %s, user agent says %s", v, major)
+		}
+	}
+	if family, ok := hintPlatforms[platform]; ok {
+		if osFamily := info.GetOsFamily(); osFamily != "" && osFamily != family {
+			report.add(AnomalyClientHintsPlatform, "client hints say %s, user agent says %s", platform, info.GetOs().Name)
+		}
+	}
+	switch mobile {
+	case "?1":
+		if info.IsDesktop() {
+			report.add(AnomalyClientHintsMobile, "client hints say mobile, user agent says desktop")
+		}
+	case "?0":
+		if info.GetDeviceType() == parser.DEVICE_TYPE_SMARTPHONE {
+			report.add(AnomalyClientHintsMobile, "client hints say not mobile, user agent says smartphone")
+		}
+	}
+}
+
+func checkOsVersion(report *ConsistencyReport, info *DeviceInfo) {
+	c, os := info.GetClient(), info.GetOs()
+	if c.Type != client.ParserNameBrowser || c.Version == "" || os.Version == "" {
+		return
+	}
+	for _, m := range minOsVersions {
+		if !parser.ArrayContainsString(m.browsers, c.ShortName) || os.ShortName != m.os {
+			continue
+		}
+		if gover.CompareSimple(majorVersion(c.Version), m.fromMajor) >= 0 &&
+			gover.CompareSimple(os.Version, m.minOs) < 0 {
+			report.add(AnomalyUnsupportedOs, "%s %s doesn't run on %s %s", c.Name, c.Version, os.Name, os.Version)
+			return
+		}
+	}
+}
+
+func checkAccept(report *ConsistencyReport, info *DeviceInfo, header http.Header) {
+	c := info.GetClient()
+	if c.Type != client.ParserNameBrowser {
+		return
+	}
+	accept := header.Get("Accept")
+	if accept == "" {
+		report.add(AnomalyMissingAccept, "%s always sends an Accept header", c.Name)
+		return
+	}
+	navigate := header.Get("Sec-Fetch-Mode") == "navigate" || header.Get("Sec-Fetch-Dest") == "document"
+	if navigate && !strings.Contains(accept, "text/html") {
+		report.add(AnomalyAcceptMismatch, "page navigation without text/html in Accept")
+	}
+	if strings.Contains(accept, "application/signed-exchange") && c.Engine != "" && c.Engine != "Blink" {
+		report.add(AnomalyAcceptMismatch, "%s (%s) accepts signed exchanges, only Blink browsers do", c.Name, c.Engine)
+	}
+}
+
+func checkBrandOs(report *ConsistencyReport, info *DeviceInfo) {
+	family := info.GetOsFamily()
+	if info.Brand == "" || family == "" {
+		return
+	}
+	apple := parser.ArrayContainsString(appleOsFamilies, family)
+	if info.Brand == "AP" && !apple {
+		report.add(AnomalyBrandOs, "Apple device running %s", info.GetOs().Name)
+	} else if info.Brand != "AP" && family == "iOS" {
+		report.add(AnomalyBrandOs, "%s device running iOS", info.GetBrandName())
+	}
+}
@@ -0,0 +1,80 @@
+package devicedetector
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	chromeWindowsUA = `Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36`
+	safariIosUA     = `Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1`
+	chromeAndroidUA = `Mozilla/5.0 (Linux; Android 4.4.2; Nexus 4 Build/KOT49H) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.43 Mobile Safari/537.36`
+	chromeAccept    = `text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7`
+)
+
+func anomalyCodes(r *ConsistencyReport) []string {
+	codes := make([]string, 0, len(r.Anomalies))
+	for _, a := range r.Anomalies {
+		codes = append(codes, a.Code)
+	}
+	return codes
+}
+
+func TestAnalyzeConsistency(t *testing.T) {
+	data := []struct {
+		ua      string
+		headers map[string]string
+		codes   []string
+	}{
+		{chromeWindowsUA, map[string]string{
+			"Accept":             chromeAccept,
+			"Sec-CH-UA":          `"Not_A Brand";v="8", "Chromium";v="120", "Google Chrome";v="120"`,
+			"Sec-CH-UA-Mobile":   "?0",
+			"Sec-CH-UA-Platform": `"Windows"`,
+			"Sec-Fetch-Mode":     "navigate",
+		}, []string{}},
+		{chromeWindowsUA, map[string]string{
+			"Accept":             "*/*",
+			"Sec-CH-UA":          `"Chromium";v="99"`,
+			"Sec-CH-UA-Mobile":   "?1",
+			"Sec-CH-UA-Platform": `"Android"`,
+			"Sec-Fetch-Dest":     "document",
+		}, []string{AnomalyClientHintsVersion, AnomalyClientHintsPlatform, AnomalyClientHintsMobile, AnomalyAcceptMismatch}},
+		{safariIosUA, map[string]string{
+			"Accept":    chromeAccept,
+			"Sec-CH-UA": `"Chromium";v="120"`,
+		}, []string{AnomalyClientHintsUnsupported, AnomalyAcceptMismatch}},
+		{chromeAndroidUA, map[string]string{
+			"Accept": chromeAccept,
+		}, []string{AnomalyUnsupportedOs}},
+		{safariIosUA, nil, []string{AnomalyMissingAccept}},
+		{`Mozilla/5.0 (Linux; Android 10; iPhone 12) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36`, map[string]string{
+			"Accept": chromeAccept,
+		}, []string{AnomalyBrandOs}},
+		// bots and libraries are not expected to look like browsers
+		{`Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)`, nil, []string{}},
+		{`curl/7.88.1`, nil, []string{}},
+	}
+	for _, item := range data {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("User-Agent", item.ua)
+		for k, v := range item.headers {
+			req.Header.Set(k, v)
+		}
+		report := dd.AnalyzeConsistency(req)
+		require.NotNil(t, report.Info)
+		require.Equal(t, item.codes, anomalyCodes(report), item.ua)
+		if len(item.codes) == 0 {
+			require.Zero(t, report.Score)
+		} else {
+			require.Positive(t, report.Score)
+			require.LessOrEqual(t, report.Score, 100)
+		}
+	}
+
+	report := dd.AnalyzeConsistency(httptest.NewRequest("GET", "/", nil))
+	require.Nil(t, report.Info)
+	require.Equal(t, []string{AnomalyMissingUserAgent}, anomalyCodes(report))
+}
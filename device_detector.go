@@ -1,12 +1,13 @@
 package devicedetector
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
 	"path/filepath"
 	"strings"
 
-	regexp "github.com/dlclark/regexp2"
-	gover "github.com/mcuadros/go-version"
-
 	"github.com/gianluca-marchini/devicedetector/parser"
 	"github.com/gianluca-marchini/devicedetector/parser/client"
 	"github.com/gianluca-marchini/devicedetector/parser/device"
@@ -26,121 +27,600 @@ var desktopOsArray = []string{
 	`Chrome OS`,
 }
 
-var (
-	chrMobReg = regexp.MustCompile(fixUserAgentRegEx(`Chrome/[\.0-9]* Mobile`), regexp.IgnoreCase)
-	chrTabReg = regexp.MustCompile(fixUserAgentRegEx(`Chrome/[\.0-9]* (?!Mobile)`), regexp.IgnoreCase)
-	opaTabReg = regexp.MustCompile(fixUserAgentRegEx(`Opera Tablet`), regexp.IgnoreCase)
-	opaTvReg  = regexp.MustCompile(fixUserAgentRegEx(`Opera TV Store`), regexp.IgnoreCase)
-)
-
 func fixUserAgentRegEx(regex string) string {
 	reg := strings.ReplaceAll(regex, `/`, `\/`)
 	reg = strings.ReplaceAll(reg, `++`, `+`)
 	return `(?:^|[^A-Z_-])(?:` + reg + `)`
 }
 
+// Default order of the client parsers: the first one detecting a client wins
+var DefaultClientParsers = []string{
+	client.ParserNameFeedReader,
+	client.ParserNameMobileApp,
+	client.ParserNameMediaPlayer,
+	client.ParserNamePim,
+	client.ParserNameBrowser,
+	client.ParserNameLibrary,
+	client.ParserNameDarwinApp,
+}
+
+// Default order of the device parsers: the first one detecting a device wins
+var DefaultDeviceParsers = []string{
+	device.ParserNameAppleModel,
+	device.ParserNameHbbTv,
+	device.ParserNameConsole,
+	device.ParserNameCar,
+	device.ParserNameCamera,
+	device.ParserNamePortableMediaPlayer,
+	device.ParserNameMobile,
+}
+
+// Default order of the bot parsers
+var DefaultBotParsers = []string{
+	parser.ParserNameBot,
+}
+
 type DeviceDetector struct {
 	cache                 *Cache
-	deviceParsers         []device.DeviceParser
-	clientParsers         []client.ClientParser
-	botParsers            []parser.BotParser
+	deviceParsers         parserChain[device.DeviceParser]
+	clientParsers         parserChain[client.ClientParser]
+	botParsers            parserChain[parser.BotParser]
+	heuristics            parserChain[Heuristic]
 	osParsers             []parser.OsParser
 	vendorParser          *parser.VendorFragments
+	automationParser      *parser.Automation
+	scannerParser         *parser.Scanners
+	inAppBrowserParser    *client.InAppBrowser
+	webViewParser         *client.WebView
+	appHintsParser        *client.AppHints
+	modelAliases          *device.ModelAliases
 	DiscardBotInformation bool
 	SkipBotDetection      bool
+	// Skip the catch-all bot patterns tried once no bot rule matched
+	SkipGenericBotDetection bool
+	// Skip the headless browsers and automation frameworks detection
+	SkipAutomationDetection bool
+	// Skip the attack tools and vulnerability scanners detection
+	SkipScannerDetection bool
+	// Skip the in-app browsers detection
+	SkipInAppBrowserDetection bool
+	// Skip the webviews detection
+	SkipWebViewDetection bool
+	// Rename the model codes (SM-G991B...) to their marketing names, keeping
+	// the code in RawModel
+	NormalizeModels bool
+	// Truncation of the os and client versions, on top of the one set with
+	// parser.SetVersionTruncation
+	versionTruncation int
 }
 
 // Initialize the device detector.
 // - dir: path of the folder containing the regexes to parse the userAgent
 // - enableCache: if true, the cache will be enabled.
-func NewDeviceDetector(dir string, enableCache bool) (*DeviceDetector, error) {
-	vp, err := parser.NewVendor(filepath.Join(dir, parser.FixtureFileVendor))
+// - overlayDirs: optional folders laid out like dir (bots.yml, oss.yml,
+// client/*.yml, device/*.yml) whose rules take precedence over the bundled
+// ones. They are applied in order, so the last folder wins.
+func NewDeviceDetector(dir string, enableCache bool, overlayDirs ...string) (*DeviceDetector, error) {
+	return NewDeviceDetectorFS(nil, dir, enableCache, overlayDirs...)
+}
+
+// Initialize the device detector with the regexes of the folder dir of fsys,
+// e.g. regexes.FS and ".". A nil fsys reads dir from the local disk. The
+// overlay folders are always read from the local disk.
+func NewDeviceDetectorFS(fsys fs.FS, dir string, enableCache bool, overlayDirs ...string) (*DeviceDetector, error) {
+	d, err := newDeviceDetector(fsys, dir, enableCache, DefaultClientParsers, DefaultDeviceParsers, DefaultBotParsers)
 	if err != nil {
 		return nil, err
 	}
 
-	osp, err := parser.NewOss(filepath.Join(dir, parser.FixtureFileOs))
+	for _, overlayDir := range overlayDirs {
+		if err := d.ApplyOverlay(overlayDir); err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+// The tables added on top of the original regexes are optional: without
+// their file, the feature is disabled and the parser left nil
+func optional(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func newDeviceDetector(fsys fs.FS, dir string, enableCache bool, clientNames, deviceNames, botNames []string) (*DeviceDetector, error) {
+	vp, err := parser.NewVendor(fsys, filepath.Join(dir, parser.FixtureFileVendor))
 	if err != nil {
 		return nil, err
 	}
 
+	osp, err := parser.NewOss(fsys, filepath.Join(dir, parser.FixtureFileOs))
+	if err != nil {
+		return nil, err
+	}
+
+	osParsers := []parser.OsParser{osp}
+	dop, err := parser.NewDarwinOs(fsys, filepath.Join(dir, parser.FixtureFileDarwin))
+	if err = optional(err); err != nil {
+		return nil, err
+	}
+	if dop != nil {
+		osParsers = []parser.OsParser{dop, osp}
+	}
+
+	ap, err := parser.NewAutomation(fsys, filepath.Join(dir, parser.FixtureFileAutomation))
+	if err = optional(err); err != nil {
+		return nil, err
+	}
+
+	sp, err := parser.NewScanners(fsys, filepath.Join(dir, parser.FixtureFileScanner))
+	if err = optional(err); err != nil {
+		return nil, err
+	}
+
+	iap, err := client.NewInAppBrowser(fsys, filepath.Join(dir, "client", client.FixtureFileInAppBrowser))
+	if err = optional(err); err != nil {
+		return nil, err
+	}
+
+	wvp, err := client.NewWebView(fsys, filepath.Join(dir, "client", client.FixtureFileWebView))
+	if err = optional(err); err != nil {
+		return nil, err
+	}
+
+	ahp, err := client.NewAppHints(fsys,
+		filepath.Join(dir, "client", client.FixtureFileAppHints),
+		filepath.Join(dir, "client", client.FixtureFileBrowserHints))
+	if err = optional(err); err != nil {
+		return nil, err
+	}
+
+	mas, err := device.NewModelAliases(fsys, filepath.Join(dir, "device", device.FixtureFileModelAlias))
+	if err = optional(err); err != nil {
+		return nil, err
+	}
+
 	d := &DeviceDetector{
-		cache:        nil,
-		vendorParser: vp,
-		osParsers:    []parser.OsParser{osp},
+		cache:              nil,
+		vendorParser:       vp,
+		osParsers:          osParsers,
+		automationParser:   ap,
+		scannerParser:      sp,
+		inAppBrowserParser: iap,
+		webViewParser:      wvp,
+		appHintsParser:     ahp,
+		modelAliases:       mas,
+		versionTruncation:  parser.VERSION_TRUNCATION_NONE,
 	}
 
 	if enableCache {
 		d.cache = NewCache()
 	}
 
-	clientDir := filepath.Join(dir, "client")
-	d.clientParsers = client.NewClientParsers(clientDir,
-		[]string{
-			client.ParserNameFeedReader,
-			client.ParserNameMobileApp,
-			client.ParserNameMediaPlayer,
-			client.ParserNamePim,
-			client.ParserNameBrowser,
-			client.ParserNameLibrary,
-		})
-
-	deviceDir := filepath.Join(dir, "device")
-	d.deviceParsers = device.NewDeviceParsers(deviceDir,
-		[]string{
-			device.ParserNameHbbTv,
-			device.ParserNameConsole,
-			device.ParserNameCar,
-			device.ParserNameCamera,
-			device.ParserNamePortableMediaPlayer,
-			device.ParserNameMobile,
-		})
+	if err := d.loadParsers(fsys, dir, clientNames, deviceNames, botNames); err != nil {
+		return nil, err
+	}
 
-	d.botParsers = []parser.BotParser{
-		parser.NewBot(filepath.Join(dir, parser.FixtureFileBot)),
+	for _, rule := range DefaultHeuristics() {
+		if err := d.AddHeuristic(rule); err != nil {
+			return nil, err
+		}
 	}
 
 	return d, nil
 }
 
+// Build the parser chains in the given order, loading the regexes from dir
+// of fsys
+func (d *DeviceDetector) loadParsers(fsys fs.FS, dir string, clientNames, deviceNames, botNames []string) error {
+	d.clientParsers = parserChain[client.ClientParser]{}
+	for i, cp := range client.NewClientParsers(fsys, filepath.Join(dir, "client"), clientNames) {
+		if isNilParser(cp) {
+			return fmt.Errorf("unable to load client parser %q", clientNames[i])
+		}
+		if err := d.clientParsers.insert(i, clientNames[i], cp); err != nil {
+			return err
+		}
+	}
+	d.deviceParsers = parserChain[device.DeviceParser]{}
+	for i, dp := range device.NewDeviceParsers(fsys, filepath.Join(dir, "device"), deviceNames) {
+		if isNilParser(dp) {
+			return fmt.Errorf("unable to load device parser %q", deviceNames[i])
+		}
+		if err := d.deviceParsers.insert(i, deviceNames[i], dp); err != nil {
+			return err
+		}
+	}
+	d.botParsers = parserChain[parser.BotParser]{}
+	for i, name := range botNames {
+		bp := parser.NewBotParser(fsys, dir, name)
+		if isNilParser(bp) {
+			return fmt.Errorf("unable to load bot parser %q", name)
+		}
+		if err := d.botParsers.insert(i, name, bp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Merge the user-defined rules found in dir ahead of the loaded ones.
+// Only the parsers implementing parser.Overlayer are affected.
+func (d *DeviceDetector) ApplyOverlay(dir string) error {
+	if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+	for _, p := range d.osParsers {
+		if o, ok := p.(parser.Overlayer); ok {
+			if err := o.ApplyOverlay(dir); err != nil {
+				return err
+			}
+		}
+	}
+	for _, p := range d.botParsers.parsers {
+		if o, ok := p.(parser.Overlayer); ok {
+			if err := o.ApplyOverlay(dir); err != nil {
+				return err
+			}
+		}
+	}
+	if d.automationParser != nil {
+		if err := d.automationParser.ApplyOverlay(dir); err != nil {
+			return err
+		}
+	}
+	if d.scannerParser != nil {
+		if err := d.scannerParser.ApplyOverlay(dir); err != nil {
+			return err
+		}
+	}
+	clientDir := filepath.Join(dir, "client")
+	if d.inAppBrowserParser != nil {
+		if err := d.inAppBrowserParser.ApplyOverlay(clientDir); err != nil {
+			return err
+		}
+	}
+	if d.webViewParser != nil {
+		if err := d.webViewParser.ApplyOverlay(clientDir); err != nil {
+			return err
+		}
+	}
+	if d.appHintsParser != nil {
+		if err := d.appHintsParser.ApplyOverlay(clientDir); err != nil {
+			return err
+		}
+	}
+	for _, p := range d.clientParsers.parsers {
+		if o, ok := p.(parser.Overlayer); ok {
+			if err := o.ApplyOverlay(clientDir); err != nil {
+				return err
+			}
+		}
+	}
+	deviceDir := filepath.Join(dir, "device")
+	if d.modelAliases != nil {
+		if err := d.modelAliases.ApplyOverlay(deviceDir); err != nil {
+			return err
+		}
+	}
+	for _, p := range d.deviceParsers.parsers {
+		if o, ok := p.(parser.Overlayer); ok {
+			if err := o.ApplyOverlay(deviceDir); err != nil {
+				return err
+			}
+		}
+	}
+	d.PurgeCache()
+	return nil
+}
+
+// Append a client parser to the chain, under an automatically generated
+// name (custom-1, custom-2...). Use the Insert methods to choose the name.
 func (d *DeviceDetector) AddClientParser(cp client.ClientParser) {
-	d.clientParsers = append(d.clientParsers, cp)
+	d.clientParsers.add(cp)
+	d.PurgeCache()
 }
 
 func (d *DeviceDetector) GetClientParser() []client.ClientParser {
-	return d.clientParsers
+	return d.clientParsers.parsers
+}
+
+// Returns the names of the client parsers, in the order they are tried
+func (d *DeviceDetector) ClientParserNames() []string {
+	return d.clientParsers.list()
+}
+
+// Insert a client parser named name before the parser named ref
+func (d *DeviceDetector) InsertClientParserBefore(ref, name string, cp client.ClientParser) error {
+	defer d.PurgeCache()
+	return d.clientParsers.insertBefore(ref, name, cp)
+}
+
+// Insert a client parser named name after the parser named ref
+func (d *DeviceDetector) InsertClientParserAfter(ref, name string, cp client.ClientParser) error {
+	defer d.PurgeCache()
+	return d.clientParsers.insertAfter(ref, name, cp)
 }
 
+// Replace the client parser named name, keeping its position
+func (d *DeviceDetector) ReplaceClientParser(name string, cp client.ClientParser) error {
+	defer d.PurgeCache()
+	return d.clientParsers.replace(name, cp)
+}
+
+func (d *DeviceDetector) RemoveClientParser(name string) error {
+	defer d.PurgeCache()
+	return d.clientParsers.remove(name)
+}
+
+// Append a device parser to the chain, under an automatically generated
+// name (custom-1, custom-2...). Use the Insert methods to choose the name.
 func (d *DeviceDetector) AddDeviceParser(dp device.DeviceParser) {
-	d.deviceParsers = append(d.deviceParsers, dp)
+	d.deviceParsers.add(dp)
+	d.PurgeCache()
 }
 
 func (d *DeviceDetector) GetDeviceParsers() []device.DeviceParser {
-	return d.deviceParsers
+	return d.deviceParsers.parsers
+}
+
+// Returns the names of the device parsers, in the order they are tried
+func (d *DeviceDetector) DeviceParserNames() []string {
+	return d.deviceParsers.list()
 }
 
+// Insert a device parser named name before the parser named ref
+func (d *DeviceDetector) InsertDeviceParserBefore(ref, name string, dp device.DeviceParser) error {
+	defer d.PurgeCache()
+	return d.deviceParsers.insertBefore(ref, name, dp)
+}
+
+// Insert a device parser named name after the parser named ref
+func (d *DeviceDetector) InsertDeviceParserAfter(ref, name string, dp device.DeviceParser) error {
+	defer d.PurgeCache()
+	return d.deviceParsers.insertAfter(ref, name, dp)
+}
+
+// Replace the device parser named name, keeping its position
+func (d *DeviceDetector) ReplaceDeviceParser(name string, dp device.DeviceParser) error {
+	defer d.PurgeCache()
+	return d.deviceParsers.replace(name, dp)
+}
+
+func (d *DeviceDetector) RemoveDeviceParser(name string) error {
+	defer d.PurgeCache()
+	return d.deviceParsers.remove(name)
+}
+
+// Append a bot parser to the chain, under an automatically generated
+// name (custom-1, custom-2...). Use the Insert methods to choose the name.
 func (d *DeviceDetector) AddBotParser(op parser.BotParser) {
-	d.botParsers = append(d.botParsers, op)
+	d.botParsers.add(op)
+	d.PurgeCache()
 }
 
 func (d *DeviceDetector) GetBotParsers() []parser.BotParser {
-	return d.botParsers
+	return d.botParsers.parsers
+}
+
+// Returns the names of the bot parsers, in the order they are tried
+func (d *DeviceDetector) BotParserNames() []string {
+	return d.botParsers.list()
+}
+
+// Insert a bot parser named name before the parser named ref
+func (d *DeviceDetector) InsertBotParserBefore(ref, name string, bp parser.BotParser) error {
+	defer d.PurgeCache()
+	return d.botParsers.insertBefore(ref, name, bp)
+}
+
+// Insert a bot parser named name after the parser named ref
+func (d *DeviceDetector) InsertBotParserAfter(ref, name string, bp parser.BotParser) error {
+	defer d.PurgeCache()
+	return d.botParsers.insertAfter(ref, name, bp)
+}
+
+// Replace the bot parser named name, keeping its position
+func (d *DeviceDetector) ReplaceBotParser(name string, bp parser.BotParser) error {
+	defer d.PurgeCache()
+	return d.botParsers.replace(name, bp)
+}
+
+func (d *DeviceDetector) RemoveBotParser(name string) error {
+	defer d.PurgeCache()
+	return d.botParsers.remove(name)
+}
+
+// Returns the names of the device type heuristics, in the order they are applied
+func (d *DeviceDetector) HeuristicNames() []string {
+	return d.heuristics.list()
+}
+
+// Append a device type heuristic, applied after the registered ones
+func (d *DeviceDetector) AddHeuristic(rule Heuristic) error {
+	defer d.PurgeCache()
+	return d.heuristics.insert(len(d.heuristics.names), rule.Name(), rule)
+}
+
+// Insert a device type heuristic before the one named ref
+func (d *DeviceDetector) InsertHeuristicBefore(ref string, rule Heuristic) error {
+	defer d.PurgeCache()
+	return d.heuristics.insertBefore(ref, rule.Name(), rule)
+}
+
+// Insert a device type heuristic after the one named ref
+func (d *DeviceDetector) InsertHeuristicAfter(ref string, rule Heuristic) error {
+	defer d.PurgeCache()
+	return d.heuristics.insertAfter(ref, rule.Name(), rule)
+}
+
+// Disable the device type heuristic named name
+func (d *DeviceDetector) RemoveHeuristic(name string) error {
+	defer d.PurgeCache()
+	return d.heuristics.remove(name)
+}
+
+// Add a bot detection rule, tried before the loaded ones.
+// The regex follows the same rules as the entries of bots.yml.
+func (d *DeviceDetector) AddBotRule(regex string, r parser.BotMatchResult) error {
+	for _, p := range d.botParsers.parsers {
+		if b, ok := p.(interface {
+			AddRule(string, parser.BotMatchResult) error
+		}); ok {
+			if err := b.AddRule(regex, r); err != nil {
+				return err
+			}
+			d.PurgeCache()
+			return nil
+		}
+	}
+	return errors.New("no bot parser accepting rules")
+}
+
+// Add a browser detection rule, tried before the loaded ones.
+// name must be one of the known browsers; name and version may reference
+// the regex groups ($1, $2...).
+func (d *DeviceDetector) AddBrowserRule(regex, name, version string) error {
+	b := d.browserParser()
+	if b == nil {
+		return errors.New("no browser parser accepting rules")
+	}
+	if err := b.AddRule(regex, name, version, nil); err != nil {
+		return err
+	}
+	d.PurgeCache()
+	return nil
+}
+
+// The first browser parser of the client chain, if any
+func (d *DeviceDetector) browserParser() *client.Browser {
+	for _, p := range d.clientParsers.parsers {
+		if b, ok := p.(*client.Browser); ok && b != nil {
+			return b
+		}
+	}
+	return nil
+}
+
+// Add an operating system detection rule, tried before the loaded ones.
+// name and version may reference the regex groups ($1, $2...).
+func (d *DeviceDetector) AddOsRule(regex, name, version string) error {
+	for _, p := range d.osParsers {
+		if o, ok := p.(interface {
+			AddRule(string, string, string) error
+		}); ok {
+			if err := o.AddRule(regex, name, version); err != nil {
+				return err
+			}
+			d.PurgeCache()
+			return nil
+		}
+	}
+	return errors.New("no os parser accepting rules")
+}
+
+// Add a device detection rule for brand (full name, as in mobiles.yml),
+// tried before the loaded ones by the mobile device parser. The device type
+// and model are those of the first matching model (Device and Model).
+func (d *DeviceDetector) AddDeviceRule(brand, regex string, models ...*device.Model) error {
+	for _, p := range d.deviceParsers.parsers {
+		if m, ok := p.(*device.Mobile); ok && m != nil {
+			if err := m.AddRule(brand, regex, models...); err != nil {
+				return err
+			}
+			d.PurgeCache()
+			return nil
+		}
+	}
+	return errors.New("no device parser accepting rules")
 }
 
 func (d *DeviceDetector) ParseBot(ua string) *parser.BotMatchResult {
 	if !d.SkipBotDetection {
-		for i := 0; i < len(d.botParsers); i++ {
-			p := d.botParsers[i]
+		for i := 0; i < len(d.botParsers.parsers); i++ {
+			p := d.botParsers.parsers[i]
 			p.DiscardDetails(d.DiscardBotInformation)
 			if r := p.Parse(ua); r != nil {
 				return r
 			}
 		}
+		if !d.SkipGenericBotDetection {
+			for _, p := range d.botParsers.parsers {
+				if g, ok := p.(parser.GenericBotParser); ok {
+					if r := g.ParseGeneric(ua); r != nil {
+						return r
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Whether ua is the one of an attack tool or vulnerability scanner. Cheaper
+// than Parse, meant for pre-filters.
+func (d *DeviceDetector) IsScanner(ua string) bool {
+	return d.scannerParser != nil && d.scannerParser.PreMatch(ua)
+}
 
+func (d *DeviceDetector) ParseScanner(ua string) *parser.ScannerMatchResult {
+	if d.SkipScannerDetection || d.scannerParser == nil {
+		return nil
+	}
+	return d.scannerParser.Parse(ua)
+}
+
+// Detect the headless browsers and automation frameworks announcing
+// themselves in ua, or the HTTP library reported as the client
+func (d *DeviceDetector) ParseAutomation(ua string, cmr *client.ClientMatchResult) *parser.AutomationMatchResult {
+	if d.SkipAutomationDetection {
+		return nil
+	}
+	if d.automationParser != nil {
+		if r := d.automationParser.Parse(ua); r != nil {
+			return r
+		}
+	}
+	if cmr != nil && cmr.Type == client.ParserNameLibrary {
+		return &parser.AutomationMatchResult{
+			Type:    parser.AutomationHttpLibrary,
+			Name:    cmr.Name,
+			Version: cmr.Version,
+		}
 	}
 	return nil
 }
 
+// Detect the webview of an app opening links inside itself, along with the
+// browser and engine of the webview
+func (d *DeviceDetector) ParseInAppBrowser(ua string, cmr *client.ClientMatchResult) *client.InAppBrowserMatchResult {
+	if d.SkipInAppBrowserDetection || d.inAppBrowserParser == nil {
+		return nil
+	}
+	return d.inAppBrowserParser.Parse(ua, cmr)
+}
+
+// Returns the kind of webview ua comes from, client.WebViewNone for the
+// browsers
+func (d *DeviceDetector) ParseWebView(ua string) string {
+	if d.SkipWebViewDetection || d.webViewParser == nil {
+		return client.WebViewNone
+	}
+	return d.webViewParser.Parse(ua)
+}
+
+// Returns the app or browser of the package name sent by the Android
+// webviews in X-Requested-With
+func (d *DeviceDetector) ParseAppHint(pkg string) *client.ClientMatchResult {
+	if d.appHintsParser == nil {
+		return nil
+	}
+	return d.appHintsParser.Parse(pkg)
+}
+
 func (d *DeviceDetector) ParseOs(ua string) *parser.OsMatchResult {
 
 	for i := 0; i < len(d.osParsers); i++ {
@@ -154,8 +634,8 @@ func (d *DeviceDetector) ParseOs(ua string) *parser.OsMatchResult {
 }
 
 func (d *DeviceDetector) ParseClient(ua string) *client.ClientMatchResult {
-	for i := 0; i < len(d.clientParsers); i++ {
-		p := d.clientParsers[i]
+	for i := 0; i < len(d.clientParsers.parsers); i++ {
+		p := d.clientParsers.parsers[i]
 		if r := p.Parse(ua); r != nil {
 			return r
 		}
@@ -164,9 +644,12 @@ func (d *DeviceDetector) ParseClient(ua string) *client.ClientMatchResult {
 }
 
 func (d *DeviceDetector) ParseDevice(ua string) *device.DeviceMatchResult {
-	for i := 0; i < len(d.deviceParsers); i++ {
-		p := d.deviceParsers[i]
+	for i := 0; i < len(d.deviceParsers.parsers); i++ {
+		p := d.deviceParsers.parsers[i]
 		if r := p.Parse(ua); r != nil {
+			if d.NormalizeModels && d.modelAliases != nil {
+				d.modelAliases.Apply(r)
+			}
 			return r
 		}
 	}
@@ -179,77 +662,21 @@ func (d *DeviceDetector) parseInfo(info *DeviceInfo) {
 		info.Type = r.Type
 		info.Model = r.Model
 		info.Brand = r.Brand
+		info.RawModel = r.RawModel
 	}
 	// If no brand has been assigned try to match by known vendor fragments
 	if info.Brand == "" && d.vendorParser != nil {
 		info.Brand = d.vendorParser.Parse(ua)
 	}
 
-	os := info.GetOs()
-	osShortName := os.ShortName
-	osFamily := parser.GetOsFamily(osShortName)
-	osVersion := os.Version
-	cmr := info.GetClient()
-
+	osShortName := info.GetOs().ShortName
 	if info.Brand == "" && (osShortName == `ATV` || osShortName == `IOS` || osShortName == `MAC`) {
 		info.Brand = `AP`
 	}
 
 	deviceType := parser.GetDeviceType(info.Type)
-	// Chrome on Android passes the device type based on the keyword 'Mobile'
-	// If it is present the device should be a smartphone, otherwise it's a tablet
-	// See https://developer.chrome.com/multidevice/user-agent#chrome_for_android_user_agent
-	if deviceType == parser.DEVICE_TYPE_INVALID && osFamily == `Android` {
-		if browserName, ok := client.GetBrowserFamily(cmr.ShortName); ok && browserName == `Chrome` {
-			if ok, _ := chrMobReg.MatchString(ua); ok {
-				deviceType = parser.DEVICE_TYPE_SMARTPHONE
-			} else if ok, _ = chrTabReg.MatchString(ua); ok {
-				deviceType = parser.DEVICE_TYPE_TABLET
-			}
-		}
-	}
-
-	if deviceType == parser.DEVICE_TYPE_INVALID {
-		if info.HasAndroidMobileFragment() {
-			deviceType = parser.DEVICE_TYPE_TABLET
-		} else if ok, _ := opaTabReg.MatchString(ua); ok {
-			deviceType = parser.DEVICE_TYPE_TABLET
-		} else if info.HasAndroidMobileFragment() {
-			deviceType = parser.DEVICE_TYPE_SMARTPHONE
-		} else if osShortName == "AND" && osVersion != "" {
-			if gover.CompareSimple(osVersion, `2.0`) == -1 {
-				deviceType = parser.DEVICE_TYPE_SMARTPHONE
-			} else if gover.CompareSimple(osVersion, `3.0`) >= 0 &&
-				gover.CompareSimple(osVersion, `4.0`) == -1 {
-				deviceType = parser.DEVICE_TYPE_TABLET
-			}
-		}
-	}
-
-	// All detected feature phones running android are more likely a smartphone
-	if deviceType == parser.DEVICE_TYPE_FEATURE_PHONE && osFamily == `Android` {
-		deviceType = parser.DEVICE_TYPE_SMARTPHONE
-	}
-
-	// According to http://msdn.microsoft.com/en-us/library/ie/hh920767(v=vs.85).aspx
-	if deviceType == parser.DEVICE_TYPE_INVALID &&
-		(osShortName == `WRT` || (osShortName == `WIN` && gover.CompareSimple(osVersion, `8`) >= 0)) &&
-		info.IsTouchEnabled() {
-		deviceType = parser.DEVICE_TYPE_TABLET
-	}
-
-	// All devices running Opera TV Store are assumed to be a tv
-	if ok, _ := opaTvReg.MatchString(ua); ok {
-		deviceType = parser.DEVICE_TYPE_TV
-	}
-
-	// Devices running Kylo or Espital TV Browsers are assumed to be a TV
-	if deviceType == parser.DEVICE_TYPE_INVALID {
-		if cmr.Name == `Kylo` || cmr.Name == `Espial TV Browser` {
-			deviceType = parser.DEVICE_TYPE_TV
-		} else if info.IsDesktop() {
-			deviceType = parser.DEVICE_TYPE_DESKTOP
-		}
+	for _, rule := range d.heuristics.parsers {
+		deviceType = rule.Apply(info, deviceType)
 	}
 
 	if deviceType != parser.DEVICE_TYPE_INVALID {
@@ -257,6 +684,15 @@ func (d *DeviceDetector) parseInfo(info *DeviceInfo) {
 	}
 }
 
+// Truncate the os and client versions of this detector only, t being one of
+// the parser.VERSION_TRUNCATION constants
+func (d *DeviceDetector) SetVersionTruncation(t int) {
+	if parser.IsVersionTruncation(t) {
+		d.versionTruncation = t
+		d.PurgeCache()
+	}
+}
+
 // Cache the deviceInfo if the cache is enabled
 func (d *DeviceDetector) cacheDeviceInfo(ua string, deviceInfo *DeviceInfo) *DeviceInfo {
 	if d.cache != nil {
@@ -293,17 +729,30 @@ func (d *DeviceDetector) Parse(ua string) *DeviceInfo {
 		userAgent: ua,
 	}
 
+	info.scanner = d.ParseScanner(ua)
+
 	info.bot = d.ParseBot(ua)
 	if info.IsBot() {
 		return d.cacheDeviceInfo(ua, info)
 	}
 
 	info.os = d.ParseOs(ua)
+	if info.os != nil {
+		info.os.Version = parser.TruncateVersion(info.os.Version, d.versionTruncation)
+	}
 
 	// Parse Clients
 	// Clients might be browsers, Feed Readers, Mobile Apps, Media Players or
 	// any other application accessing with an parseable UA
 	info.client = d.ParseClient(ua)
+	if info.client != nil {
+		info.client.Version = parser.TruncateVersion(info.client.Version, d.versionTruncation)
+	}
+
+	info.automation = d.ParseAutomation(ua, info.client)
+
+	info.inAppBrowser = d.ParseInAppBrowser(ua, info.client)
+	info.webView = d.ParseWebView(ua)
 
 	d.parseInfo(info)
 
@@ -0,0 +1,180 @@
+package devicedetector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/gianluca-marchini/devicedetector/parser"
+)
+
+const (
+	// Regexes read from a folder on disk
+	RegexesSourceDir = "dir"
+	// Regexes bundled in the binary, set RegexesConfig.FS to regexes.FS
+	RegexesSourceEmbedded = "embedded"
+)
+
+// Version truncation names accepted in the configuration
+var versionTruncations = map[string]int{
+	"major": parser.VERSION_TRUNCATION_MAJOR,
+	"minor": parser.VERSION_TRUNCATION_MINOR,
+	"patch": parser.VERSION_TRUNCATION_PATCH,
+	"build": parser.VERSION_TRUNCATION_BUILD,
+	"none":  parser.VERSION_TRUNCATION_NONE,
+}
+
+type RegexesConfig struct {
+	// dir (default) or embedded
+	Source string `yaml:"source" json:"source"`
+	// Folder containing the regexes, when the source is dir
+	Dir string `yaml:"dir" json:"dir"`
+	// Overlay folders, applied in order on top of the regexes
+	Overlays []string `yaml:"overlays" json:"overlays"`
+	// Regexes of the embedded source, usually regexes.FS. Left to the
+	// caller so that only the programs using them link the bundled files.
+	FS fs.FS `yaml:"-" json:"-"`
+}
+
+type CacheConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+}
+
+type BotsConfig struct {
+	SkipDetection      bool `yaml:"skip_detection" json:"skip_detection"`
+	DiscardInformation bool `yaml:"discard_information" json:"discard_information"`
+	SkipGeneric        bool `yaml:"skip_generic" json:"skip_generic"`
+}
+
+type DevicesConfig struct {
+	// Rename the model codes to their marketing names (model_aliases.yml)
+	ModelAliases bool `yaml:"model_aliases" json:"model_aliases"`
+}
+
+// Declarative description of a device detector
+type Config struct {
+	Regexes RegexesConfig `yaml:"regexes" json:"regexes"`
+	// Parser names in the order they are tried, empty for the default order
+	ClientParsers []string    `yaml:"client_parsers" json:"client_parsers"`
+	DeviceParsers []string    `yaml:"device_parsers" json:"device_parsers"`
+	BotParsers    []string    `yaml:"bot_parsers" json:"bot_parsers"`
+	Cache         CacheConfig `yaml:"cache" json:"cache"`
+	// major, minor, patch, build or none; empty for none, on top of the
+	// truncation set with parser.SetVersionTruncation
+	VersionTruncation string        `yaml:"version_truncation" json:"version_truncation"`
+	Bots              BotsConfig    `yaml:"bots" json:"bots"`
+	Devices           DevicesConfig `yaml:"devices" json:"devices"`
+	// Names of the built-in device type heuristics to apply, in order;
+	// empty for all of them in the default order
+	Heuristics []string `yaml:"heuristics" json:"heuristics"`
+}
+
+// Read a configuration file, in JSON when its extension is .json and in
+// YAML otherwise. Unknown fields are rejected.
+func LoadConfig(file string) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	if strings.EqualFold(filepath.Ext(file), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(cfg)
+	} else {
+		err = yaml.UnmarshalStrict(data, cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return cfg, nil
+}
+
+// Initialize the device detector described by cfg
+func NewDeviceDetectorFromConfig(cfg *Config) (*DeviceDetector, error) {
+	var fsys fs.FS
+	dir := cfg.Regexes.Dir
+	switch cfg.Regexes.Source {
+	case "", RegexesSourceDir:
+		if dir == "" {
+			return nil, fmt.Errorf("missing regexes dir")
+		}
+	case RegexesSourceEmbedded:
+		if cfg.Regexes.FS == nil {
+			return nil, fmt.Errorf("missing regexes FS for the embedded source")
+		}
+		fsys = cfg.Regexes.FS
+		dir = "."
+	default:
+		return nil, fmt.Errorf("unknown regexes source %q", cfg.Regexes.Source)
+	}
+
+	truncation := parser.VERSION_TRUNCATION_NONE
+	if cfg.VersionTruncation != "" {
+		t, ok := versionTruncations[strings.ToLower(cfg.VersionTruncation)]
+		if !ok {
+			return nil, fmt.Errorf("unknown version truncation %q", cfg.VersionTruncation)
+		}
+		truncation = t
+	}
+
+	clientNames := cfg.ClientParsers
+	if len(clientNames) == 0 {
+		clientNames = DefaultClientParsers
+	}
+	deviceNames := cfg.DeviceParsers
+	if len(deviceNames) == 0 {
+		deviceNames = DefaultDeviceParsers
+	}
+	botNames := cfg.BotParsers
+	if len(botNames) == 0 {
+		botNames = DefaultBotParsers
+	}
+
+	d, err := newDeviceDetector(fsys, dir, cfg.Cache.Enabled, clientNames, deviceNames, botNames)
+	if err != nil {
+		return nil, err
+	}
+	for _, overlayDir := range cfg.Regexes.Overlays {
+		if err := d.ApplyOverlay(overlayDir); err != nil {
+			return nil, err
+		}
+	}
+	if len(cfg.Heuristics) > 0 {
+		if err := d.setHeuristics(cfg.Heuristics); err != nil {
+			return nil, err
+		}
+	}
+	d.SkipBotDetection = cfg.Bots.SkipDetection
+	d.DiscardBotInformation = cfg.Bots.DiscardInformation
+	d.SkipGenericBotDetection = cfg.Bots.SkipGeneric
+	d.NormalizeModels = cfg.Devices.ModelAliases
+
+	d.SetVersionTruncation(truncation)
+	return d, nil
+}
+
+// Replace the device type heuristics with the built-in ones named names
+func (d *DeviceDetector) setHeuristics(names []string) error {
+	builtin := make(map[string]Heuristic)
+	for _, rule := range DefaultHeuristics() {
+		builtin[rule.Name()] = rule
+	}
+	d.heuristics = parserChain[Heuristic]{}
+	for _, name := range names {
+		rule, ok := builtin[name]
+		if !ok {
+			return fmt.Errorf("unknown heuristic %q", name)
+		}
+		if err := d.AddHeuristic(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
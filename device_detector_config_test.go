@@ -0,0 +1,96 @@
+package devicedetector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gianluca-marchini/devicedetector/parser"
+	"github.com/gianluca-marchini/devicedetector/parser/client"
+	"github.com/gianluca-marchini/devicedetector/parser/device"
+	"github.com/gianluca-marchini/devicedetector/regexes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDeviceDetectorFromConfig(t *testing.T) {
+	parser.ResetParserAbstract()
+
+	cfg, err := LoadConfig(`fixtures/config.yml`)
+	require.NoError(t, err)
+
+	cd, err := NewDeviceDetectorFromConfig(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, cd.cache)
+	require.True(t, cd.DiscardBotInformation)
+	require.Equal(t, []string{client.ParserNameMobileApp, client.ParserNameBrowser}, cd.ClientParserNames())
+	require.Equal(t, []string{device.ParserNameMobile}, cd.DeviceParserNames())
+	require.Equal(t, []string{HeuristicChromeAndroid, HeuristicAndroidTabletFragment, HeuristicAndroidMobileFragment}, cd.HeuristicNames())
+
+	info := cd.Parse(`Mozilla/5.0 (Linux; Android 4.2.2; ARCHOS 101 PLATINUM Build/JDQ39) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/34.0.1847.114 Safari/537.36`)
+	require.Equal(t, `34.0`, info.GetClient().Version)
+	require.Equal(t, `101 Platinum`, info.GetModel())
+	require.True(t, cd.Parse(`AcmeMonitor/1.0`).IsBot())
+
+	// the truncation is the detector's own
+	require.Equal(t, `34.0.1847.114`, dd.Parse(info.userAgent).GetClient().Version)
+}
+
+func TestNewDeviceDetectorFromEmbeddedConfig(t *testing.T) {
+	parser.ResetParserAbstract()
+
+	cfg, err := LoadConfig(`fixtures/config.json`)
+	require.NoError(t, err)
+	_, err = NewDeviceDetectorFromConfig(cfg)
+	require.Error(t, err)
+
+	cfg.Regexes.FS = regexes.FS
+	cd, err := NewDeviceDetectorFromConfig(cfg)
+	require.NoError(t, err)
+	require.Nil(t, cd.cache)
+	require.True(t, cd.SkipBotDetection)
+	require.Equal(t, []string{client.ParserNameBrowser, client.ParserNameLibrary}, cd.ClientParserNames())
+	require.Equal(t, DefaultDeviceParsers, cd.DeviceParserNames())
+	require.Len(t, cd.HeuristicNames(), len(DefaultHeuristics()))
+
+	info := cd.Parse(`Mozilla/5.0 (Linux; Android 4.4.2; Nexus 4 Build/KOT49H) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/33.0.1750.136 Mobile Safari/537.36`)
+	require.Equal(t, `Google`, info.GetBrandName())
+	require.Equal(t, `Chrome Mobile`, info.GetClient().Name)
+
+	require.True(t, cd.NormalizeModels)
+	info = cd.Parse(`Mozilla/5.0 (Linux; Android 12; SM-G991B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/108.0.5359.128 Mobile Safari/537.36`)
+	require.Equal(t, `Galaxy S21 5G`, info.GetModel())
+	require.Equal(t, `SM-G991B`, info.GetRawModel())
+}
+
+func TestNewDeviceDetectorFromInvalidConfig(t *testing.T) {
+	parser.ResetParserAbstract()
+
+	configs := []*Config{
+		{},
+		{Regexes: RegexesConfig{Source: `ftp`}},
+		{Regexes: RegexesConfig{Dir: `regexes`}, VersionTruncation: `micro`},
+		{Regexes: RegexesConfig{Dir: `regexes`}, ClientParsers: []string{`missing`}},
+		{Regexes: RegexesConfig{Dir: `regexes`}, Heuristics: []string{`missing`}},
+		// the IAB files aren't bundled
+		{Regexes: RegexesConfig{Dir: `regexes`}, BotParsers: []string{parser.ParserNameBot, parser.ParserNameIabBot}},
+		{Regexes: RegexesConfig{Dir: `regexes`, Overlays: []string{`fixtures/missing-overlay`}}},
+	}
+	for i, cfg := range configs {
+		_, err := NewDeviceDetectorFromConfig(cfg)
+		require.Error(t, err, i)
+	}
+}
+
+func TestLoadConfigUnknownField(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		`config.yml`:  "regexes:\n  dir: regexes\nversion_trucation: minor\n",
+		`config.json`: `{"regexes": {"dir": "regexes"}, "version_trucation": "minor"}`,
+	}
+	for name, data := range files {
+		file := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(file, []byte(data), 0o644))
+		_, err := LoadConfig(file)
+		require.Error(t, err, name)
+	}
+}
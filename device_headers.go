@@ -0,0 +1,118 @@
+package devicedetector
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gianluca-marchini/devicedetector/parser"
+	"github.com/gianluca-marchini/devicedetector/parser/client"
+)
+
+// Headers in which proxy browsers (Opera Mini, UC Mini...) forward the user
+// agent of the handset, in the order they are looked for
+var DeviceUserAgentHeaders = []string{
+	"X-OperaMini-Phone-UA",
+	"X-UCBrowser-Device-UA",
+	"Device-Stock-UA",
+	"X-Device-User-Agent",
+	"X-Original-User-Agent",
+}
+
+// Brands under which the browsers of the X-Requested-With packages are listed
+// in Sec-CH-UA-Full-Version-List, when it isn't their name
+var browserHintBrands = map[string]string{
+	"Chrome Mobile":              "Google Chrome",
+	"Samsung Browser":            "Samsung Internet",
+	"Opera Mobile":               "Opera",
+	"DuckDuckGo Privacy Browser": "DuckDuckGo",
+	"Yandex Browser":             "YaBrowser",
+}
+
+// Version of the browser name in the Sec-CH-UA-Full-Version-List of header,
+// if listed
+func hintBrowserVersion(header http.Header, name string) string {
+	list := header.Get("Sec-CH-UA-Full-Version-List")
+	if list == "" {
+		return ""
+	}
+	brand, ok := browserHintBrands[name]
+	if !ok {
+		brand = name
+	}
+	return parseBrands(list)[brand]
+}
+
+// User agent of the handset forwarded in header, if any
+func deviceUserAgent(header http.Header) string {
+	for _, name := range DeviceUserAgentHeaders {
+		if v := strings.TrimSpace(header.Get(name)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Parse ua along with the other request headers:
+//   - when a proxy browser forwards the user agent of the handset, the client
+//     is detected from ua and the device and os from the forwarded user agent
+//   - when an Android webview sends the package name of its app in
+//     X-Requested-With, the client is the app, or the browser for the
+//     packages of browsers with the version of Sec-CH-UA-Full-Version-List
+func (d *DeviceDetector) ParseWithHeaders(ua string, header http.Header) *DeviceInfo {
+	info := d.Parse(ua)
+	if info != nil && info.IsBot() {
+		return info
+	}
+	info = d.withDeviceUserAgent(info, ua, header)
+	return d.withRequestedWith(info, header)
+}
+
+func (d *DeviceDetector) withDeviceUserAgent(info *DeviceInfo, ua string, header http.Header) *DeviceInfo {
+	deviceUA := deviceUserAgent(header)
+	if deviceUA == "" || deviceUA == ua || !parser.StringContainsLetter(deviceUA) {
+		return info
+	}
+	deviceInfo := d.Parse(deviceUA)
+	if info == nil {
+		return deviceInfo
+	}
+	if deviceInfo == nil || deviceInfo.IsBot() {
+		return info
+	}
+
+	// the parsed infos may be cached, work on a copy
+	combined := *info
+	combined.deviceUserAgent = deviceUA
+	if deviceInfo.os != nil {
+		combined.os = deviceInfo.os
+	}
+	if deviceInfo.Model != "" || deviceInfo.Brand != "" || deviceInfo.Type != "" {
+		combined.DeviceMatchResult = deviceInfo.DeviceMatchResult
+	}
+	return &combined
+}
+
+func (d *DeviceDetector) withRequestedWith(info *DeviceInfo, header http.Header) *DeviceInfo {
+	if info == nil {
+		return nil
+	}
+	hint := d.ParseAppHint(header.Get(client.HeaderRequestedWith))
+	if hint == nil {
+		return info
+	}
+	// keep what the user agent tells about the same client
+	if c := info.GetClient(); c.Type == hint.Type && c.Name == hint.Name {
+		hint.Version = c.Version
+	}
+	if hint.Type == client.ParserNameBrowser {
+		if v := hintBrowserVersion(header, hint.Name); v != "" {
+			hint.Version = parser.TruncateVersion(v, d.versionTruncation)
+		}
+		hint.Engine = info.GetClient().Engine
+		hint.EngineVersion = info.GetClient().EngineVersion
+	}
+
+	combined := *info
+	combined.client = hint
+	return &combined
+}
@@ -0,0 +1,176 @@
+package devicedetector
+
+import (
+	regexp "github.com/dlclark/regexp2"
+	gover "github.com/mcuadros/go-version"
+
+	"github.com/gianluca-marchini/devicedetector/parser"
+	"github.com/gianluca-marchini/devicedetector/parser/client"
+)
+
+var (
+	chrMobReg = regexp.MustCompile(fixUserAgentRegEx(`Chrome/[\.0-9]* Mobile`), regexp.IgnoreCase)
+	chrTabReg = regexp.MustCompile(fixUserAgentRegEx(`Chrome/[\.0-9]* (?!Mobile)`), regexp.IgnoreCase)
+	opaTabReg = regexp.MustCompile(fixUserAgentRegEx(`Opera Tablet`), regexp.IgnoreCase)
+	opaTvReg  = regexp.MustCompile(fixUserAgentRegEx(`Opera TV Store`), regexp.IgnoreCase)
+)
+
+// Post-processing heuristic refining the device type once the device,
+// the operating system and the client have been parsed.
+// Heuristics are applied in order, each one receiving the type returned by the
+// previous one (parser.DEVICE_TYPE_INVALID while still unknown).
+type Heuristic interface {
+	Name() string
+	Apply(info *DeviceInfo, deviceType int) int
+}
+
+type heuristicFunc struct {
+	name  string
+	apply func(info *DeviceInfo, deviceType int) int
+}
+
+func (r *heuristicFunc) Name() string {
+	return r.name
+}
+
+func (r *heuristicFunc) Apply(info *DeviceInfo, deviceType int) int {
+	return r.apply(info, deviceType)
+}
+
+// Create a heuristic from a function
+func NewHeuristic(name string, apply func(info *DeviceInfo, deviceType int) int) Heuristic {
+	return &heuristicFunc{name: name, apply: apply}
+}
+
+const (
+	HeuristicChromeAndroid         = "chrome-android"
+	HeuristicAndroidTabletFragment = "android-tablet-fragment"
+	HeuristicOperaTablet           = "opera-tablet"
+	HeuristicAndroidMobileFragment = "android-mobile-fragment"
+	HeuristicAndroidVersion        = "android-version"
+	HeuristicAndroidFeaturePhone   = "android-feature-phone"
+	HeuristicWindowsTouch          = "windows-touch"
+	HeuristicOperaTvStore          = "opera-tv-store"
+	HeuristicTvBrowser             = "tv-browser"
+	HeuristicDesktopOs             = "desktop-os"
+)
+
+// Returns the built-in heuristics, in the order they are applied by default
+func DefaultHeuristics() []Heuristic {
+	return []Heuristic{
+		NewHeuristic(HeuristicChromeAndroid, chromeAndroidRule),
+		NewHeuristic(HeuristicAndroidTabletFragment, androidTabletFragmentRule),
+		NewHeuristic(HeuristicOperaTablet, operaTabletRule),
+		NewHeuristic(HeuristicAndroidMobileFragment, androidMobileFragmentRule),
+		NewHeuristic(HeuristicAndroidVersion, androidVersionRule),
+		NewHeuristic(HeuristicAndroidFeaturePhone, androidFeaturePhoneRule),
+		NewHeuristic(HeuristicWindowsTouch, windowsTouchRule),
+		NewHeuristic(HeuristicOperaTvStore, operaTvStoreRule),
+		NewHeuristic(HeuristicTvBrowser, tvBrowserRule),
+		NewHeuristic(HeuristicDesktopOs, desktopOsRule),
+	}
+}
+
+// Chrome on Android passes the device type based on the keyword 'Mobile'
+// If it is present the device should be a smartphone, otherwise it's a tablet
+// See https://developer.chrome.com/multidevice/user-agent#chrome_for_android_user_agent
+func chromeAndroidRule(info *DeviceInfo, deviceType int) int {
+	if deviceType != parser.DEVICE_TYPE_INVALID || info.GetOsFamily() != `Android` {
+		return deviceType
+	}
+	if browserName, ok := client.GetBrowserFamily(info.GetClient().ShortName); ok && browserName == `Chrome` {
+		if ok, _ := chrMobReg.MatchString(info.userAgent); ok {
+			return parser.DEVICE_TYPE_SMARTPHONE
+		} else if ok, _ = chrTabReg.MatchString(info.userAgent); ok {
+			return parser.DEVICE_TYPE_TABLET
+		}
+	}
+	return deviceType
+}
+
+// Android user agents may declare the device type with '; Tablet;'
+func androidTabletFragmentRule(info *DeviceInfo, deviceType int) int {
+	if deviceType == parser.DEVICE_TYPE_INVALID && info.HasAndroidTableFragment() {
+		return parser.DEVICE_TYPE_TABLET
+	}
+	return deviceType
+}
+
+func operaTabletRule(info *DeviceInfo, deviceType int) int {
+	if deviceType == parser.DEVICE_TYPE_INVALID {
+		if ok, _ := opaTabReg.MatchString(info.userAgent); ok {
+			return parser.DEVICE_TYPE_TABLET
+		}
+	}
+	return deviceType
+}
+
+// Android user agents may declare the device type with '; Mobile;'
+func androidMobileFragmentRule(info *DeviceInfo, deviceType int) int {
+	if deviceType == parser.DEVICE_TYPE_INVALID && info.HasAndroidMobileFragment() {
+		return parser.DEVICE_TYPE_SMARTPHONE
+	}
+	return deviceType
+}
+
+// Android up to 2.0 was only available for smartphones, Android 3.x only
+// for tablets
+func androidVersionRule(info *DeviceInfo, deviceType int) int {
+	os := info.GetOs()
+	if deviceType != parser.DEVICE_TYPE_INVALID || os.ShortName != `AND` || os.Version == "" {
+		return deviceType
+	}
+	if gover.CompareSimple(os.Version, `2.0`) == -1 {
+		return parser.DEVICE_TYPE_SMARTPHONE
+	} else if gover.CompareSimple(os.Version, `3.0`) >= 0 &&
+		gover.CompareSimple(os.Version, `4.0`) == -1 {
+		return parser.DEVICE_TYPE_TABLET
+	}
+	return deviceType
+}
+
+// All detected feature phones running android are more likely a smartphone
+func androidFeaturePhoneRule(info *DeviceInfo, deviceType int) int {
+	if deviceType == parser.DEVICE_TYPE_FEATURE_PHONE && info.GetOsFamily() == `Android` {
+		return parser.DEVICE_TYPE_SMARTPHONE
+	}
+	return deviceType
+}
+
+// According to http://msdn.microsoft.com/en-us/library/ie/hh920767(v=vs.85).aspx
+func windowsTouchRule(info *DeviceInfo, deviceType int) int {
+	os := info.GetOs()
+	if deviceType == parser.DEVICE_TYPE_INVALID &&
+		(os.ShortName == `WRT` || (os.ShortName == `WIN` && gover.CompareSimple(os.Version, `8`) >= 0)) &&
+		info.IsTouchEnabled() {
+		return parser.DEVICE_TYPE_TABLET
+	}
+	return deviceType
+}
+
+// All devices running Opera TV Store are assumed to be a tv
+func operaTvStoreRule(info *DeviceInfo, deviceType int) int {
+	if ok, _ := opaTvReg.MatchString(info.userAgent); ok {
+		return parser.DEVICE_TYPE_TV
+	}
+	return deviceType
+}
+
+// Devices running Kylo or Espital TV Browsers are assumed to be a TV
+func tvBrowserRule(info *DeviceInfo, deviceType int) int {
+	if deviceType == parser.DEVICE_TYPE_INVALID {
+		if name := info.GetClient().Name; name == `Kylo` || name == `Espial TV Browser` {
+			return parser.DEVICE_TYPE_TV
+		}
+	}
+	return deviceType
+}
+
+// Devices with an unknown type running a desktop operating system are
+// assumed to be a desktop
+func desktopOsRule(info *DeviceInfo, deviceType int) int {
+	if deviceType == parser.DEVICE_TYPE_INVALID && info.IsDesktop() {
+		return parser.DEVICE_TYPE_DESKTOP
+	}
+	return deviceType
+}
@@ -0,0 +1,142 @@
+package devicedetector
+
+import (
+	"testing"
+
+	"github.com/gianluca-marchini/devicedetector/parser"
+	"github.com/gianluca-marchini/devicedetector/parser/client"
+	"github.com/stretchr/testify/require"
+)
+
+type ruleTest struct {
+	ua       string
+	os       *parser.OsMatchResult
+	client   *client.ClientMatchResult
+	in       int
+	expected int
+}
+
+func testRule(t *testing.T, apply func(*DeviceInfo, int) int, tests []ruleTest) {
+	t.Helper()
+	for _, test := range tests {
+		info := &DeviceInfo{
+			userAgent: test.ua,
+			os:        test.os,
+			client:    test.client,
+		}
+		require.Equal(t, test.expected, apply(info, test.in), test.ua)
+	}
+}
+
+var (
+	android44 = &parser.OsMatchResult{Name: `Android`, ShortName: `AND`, Version: `4.4`}
+	chromeCM  = &client.ClientMatchResult{Type: client.ParserNameBrowser, Name: `Chrome Mobile`, ShortName: `CM`}
+)
+
+func TestChromeAndroidRule(t *testing.T) {
+	testRule(t, chromeAndroidRule, []ruleTest{
+		{`Mozilla/5.0 (Linux; Android 4.4; Nexus 5) Chrome/33.0 Mobile Safari/537.36`, android44, chromeCM, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_SMARTPHONE},
+		{`Mozilla/5.0 (Linux; Android 4.4; Nexus 7) Chrome/33.0 Safari/537.36`, android44, chromeCM, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_TABLET},
+		{`Mozilla/5.0 (Linux; Android 4.4; Nexus 7) Chrome/33.0 Safari/537.36`, android44, chromeCM, parser.DEVICE_TYPE_TV, parser.DEVICE_TYPE_TV},
+		{`Mozilla/5.0 (Linux; Android 4.4; Nexus 7) Chrome/33.0 Safari/537.36`, android44, nil, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_INVALID},
+	})
+}
+
+func TestAndroidTabletFragmentRule(t *testing.T) {
+	testRule(t, androidTabletFragmentRule, []ruleTest{
+		{`Mozilla/5.0 (Android 4.4; Tablet; rv:41.0) Gecko/41.0 Firefox/41.0`, android44, nil, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_TABLET},
+		{`Mozilla/5.0 (Android 4.4; Mobile; rv:41.0) Gecko/41.0 Firefox/41.0`, android44, nil, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_INVALID},
+	})
+}
+
+func TestOperaTabletRule(t *testing.T) {
+	testRule(t, operaTabletRule, []ruleTest{
+		{`Opera/9.80 (Android 2.3.3; Linux; Opera Tablet/ADR-1106291546; U; en) Presto/2.8.149 Version/11.10`, nil, nil, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_TABLET},
+		{`Opera/9.80 (Android 2.3.3; Linux; Opera Mobi/ADR-1106291546; U; en) Presto/2.8.149 Version/11.10`, nil, nil, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_INVALID},
+	})
+}
+
+func TestAndroidMobileFragmentRule(t *testing.T) {
+	testRule(t, androidMobileFragmentRule, []ruleTest{
+		{`Mozilla/5.0 (Android 4.4; Mobile; rv:41.0) Gecko/41.0 Firefox/41.0`, android44, nil, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_SMARTPHONE},
+		{`Mozilla/5.0 (Android 4.4; Tablet; rv:41.0) Gecko/41.0 Firefox/41.0`, android44, nil, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_INVALID},
+	})
+}
+
+func TestAndroidVersionRule(t *testing.T) {
+	testRule(t, androidVersionRule, []ruleTest{
+		{``, &parser.OsMatchResult{ShortName: `AND`, Version: `1.6`}, nil, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_SMARTPHONE},
+		{``, &parser.OsMatchResult{ShortName: `AND`, Version: `2.3`}, nil, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_INVALID},
+		{``, &parser.OsMatchResult{ShortName: `AND`, Version: `3.2`}, nil, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_TABLET},
+		{``, &parser.OsMatchResult{ShortName: `AND`, Version: `4.0`}, nil, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_INVALID},
+		{``, &parser.OsMatchResult{ShortName: `AND`}, nil, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_INVALID},
+	})
+}
+
+func TestAndroidFeaturePhoneRule(t *testing.T) {
+	testRule(t, androidFeaturePhoneRule, []ruleTest{
+		{``, android44, nil, parser.DEVICE_TYPE_FEATURE_PHONE, parser.DEVICE_TYPE_SMARTPHONE},
+		{``, &parser.OsMatchResult{ShortName: `KOS`}, nil, parser.DEVICE_TYPE_FEATURE_PHONE, parser.DEVICE_TYPE_FEATURE_PHONE},
+	})
+}
+
+func TestWindowsTouchRule(t *testing.T) {
+	testRule(t, windowsTouchRule, []ruleTest{
+		{`Mozilla/5.0 (compatible; MSIE 10.0; Windows NT 6.2; ARM; Trident/6.0; Touch)`, &parser.OsMatchResult{ShortName: `WRT`}, nil, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_TABLET},
+		{`Mozilla/5.0 (Windows NT 6.3; Trident/7.0; Touch; rv:11.0) like Gecko`, &parser.OsMatchResult{ShortName: `WIN`, Version: `8.1`}, nil, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_TABLET},
+		{`Mozilla/5.0 (Windows NT 6.1; Trident/7.0; Touch; rv:11.0) like Gecko`, &parser.OsMatchResult{ShortName: `WIN`, Version: `7`}, nil, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_INVALID},
+		{`Mozilla/5.0 (Windows NT 6.3; Trident/7.0; rv:11.0) like Gecko`, &parser.OsMatchResult{ShortName: `WIN`, Version: `8.1`}, nil, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_INVALID},
+	})
+}
+
+func TestOperaTvStoreRule(t *testing.T) {
+	testRule(t, operaTvStoreRule, []ruleTest{
+		{`Opera/9.80 (Linux mips; Opera TV Store/5581) Presto/2.12.362 Version/12.11`, nil, nil, parser.DEVICE_TYPE_SMARTPHONE, parser.DEVICE_TYPE_TV},
+		{`Opera/9.80 (Linux mips) Presto/2.12.362 Version/12.11`, nil, nil, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_INVALID},
+	})
+}
+
+func TestTvBrowserRule(t *testing.T) {
+	testRule(t, tvBrowserRule, []ruleTest{
+		{``, nil, &client.ClientMatchResult{Name: `Kylo`}, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_TV},
+		{``, nil, &client.ClientMatchResult{Name: `Espial TV Browser`}, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_TV},
+		{``, nil, &client.ClientMatchResult{Name: `Kylo`}, parser.DEVICE_TYPE_DESKTOP, parser.DEVICE_TYPE_DESKTOP},
+	})
+}
+
+func TestDesktopOsRule(t *testing.T) {
+	testRule(t, desktopOsRule, []ruleTest{
+		{``, &parser.OsMatchResult{ShortName: `WIN`}, nil, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_DESKTOP},
+		{``, &parser.OsMatchResult{ShortName: `WIN`}, &client.ClientMatchResult{Type: client.ParserNameBrowser, ShortName: `MF`}, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_INVALID},
+		{``, android44, nil, parser.DEVICE_TYPE_INVALID, parser.DEVICE_TYPE_INVALID},
+	})
+}
+
+func TestCustomHeuristic(t *testing.T) {
+	parser.ResetParserAbstract()
+
+	rd, err := NewDeviceDetector("regexes", false)
+	require.NoError(t, err)
+
+	ua := `Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/80.0.3987.87 Safari/537.36 AcmeKiosk/1.0`
+	require.Equal(t, `desktop`, rd.Parse(ua).GetDeviceName())
+
+	kiosk := NewHeuristic(`acme-kiosk`, func(info *DeviceInfo, deviceType int) int {
+		if parser.MatchUserAgent(info.GetUserAgent(), `AcmeKiosk`) != nil {
+			return parser.DEVICE_TYPE_SMART_DISPLAY
+		}
+		return deviceType
+	})
+	require.NoError(t, rd.InsertHeuristicBefore(HeuristicDesktopOs, kiosk))
+	require.Equal(t, `smart display`, rd.Parse(ua).GetDeviceName())
+
+	require.NoError(t, rd.RemoveHeuristic(`acme-kiosk`))
+	require.NoError(t, rd.RemoveHeuristic(HeuristicDesktopOs))
+	require.Equal(t, ``, rd.Parse(ua).GetDeviceName())
+	require.NotContains(t, rd.HeuristicNames(), HeuristicDesktopOs)
+	require.Error(t, rd.RemoveHeuristic(HeuristicDesktopOs))
+
+	require.NoError(t, rd.AddHeuristic(kiosk))
+	require.Equal(t, `acme-kiosk`, rd.HeuristicNames()[len(rd.HeuristicNames())-1])
+	require.Equal(t, `smart display`, rd.Parse(ua).GetDeviceName())
+}
@@ -20,6 +20,15 @@ type DeviceInfo struct {
 	client *client.ClientMatchResult
 	os     *parser.OsMatchResult
 	bot    *parser.BotMatchResult
+
+	automation   *parser.AutomationMatchResult
+	scanner      *parser.ScannerMatchResult
+	inAppBrowser *client.InAppBrowserMatchResult
+	// kind of webview, client.WebViewNone for the browsers
+	webView string
+
+	// handset user agent forwarded by a proxy browser
+	deviceUserAgent string
 }
 
 func (d *DeviceInfo) GetDeviceType() int {
@@ -30,6 +39,48 @@ func (d *DeviceInfo) IsBot() bool {
 	return d.bot != nil
 }
 
+// Whether the client is an attack tool or vulnerability scanner
+func (d *DeviceInfo) IsScanner() bool {
+	return d.scanner != nil
+}
+
+// Whether the client is a headless browser, a browser driven by an
+// automation framework or an HTTP library
+func (d *DeviceInfo) IsAutomated() bool {
+	return d.automation != nil
+}
+
+// Whether the client is the webview of an app opening links inside itself
+// (Facebook, Instagram, WeChat...), where logins and payments often break
+func (d *DeviceInfo) IsInAppBrowser() bool {
+	return d.inAppBrowser != nil
+}
+
+// Whether the client is a webview embedded in an app rather than a browser:
+// cookies and logins aren't shared with the browser of the device
+func (d *DeviceInfo) IsWebView() bool {
+	return d.webView != client.WebViewNone
+}
+
+// Kind of webview: client.WebViewAndroid, client.WebViewIos or
+// client.WebViewNone
+func (d *DeviceInfo) GetWebViewKind() string {
+	return d.webView
+}
+
+// Whether the bot collects content for AI training or AI answers
+func (d *DeviceInfo) IsAICrawler() bool {
+	return d.bot != nil && d.bot.IsAICrawler()
+}
+
+// Whether the browser loads the pages through its vendor's servers, which
+// execute or rewrite them: scripts don't run on the device and the requests
+// come from the vendor's addresses. The vendor and the mode are in the
+// ProxyVendor and ProxyMode of the client.
+func (d *DeviceInfo) IsServerRendered() bool {
+	return d.client != nil && d.client.ProxyMode != ""
+}
+
 func (d *DeviceInfo) IsTouchEnabled() bool {
 	find, _ := touchReg.MatchString(d.userAgent)
 	return find
@@ -116,6 +167,21 @@ func (d *DeviceInfo) GetBrowserClient() *client.BrowserMatchResult {
 	return &client.BrowserMatchResult{}
 }
 
+// Automation of the client, with the AutomationNone type when not automated
+func (d *DeviceInfo) GetAutomation() *parser.AutomationMatchResult {
+	if d.automation != nil {
+		return d.automation
+	}
+	return &parser.AutomationMatchResult{}
+}
+
+func (d *DeviceInfo) GetInAppBrowser() *client.InAppBrowserMatchResult {
+	if d.inAppBrowser != nil {
+		return d.inAppBrowser
+	}
+	return &client.InAppBrowserMatchResult{}
+}
+
 func (d *DeviceInfo) GetDevice() *device.DeviceMatchResult {
 	return &d.DeviceMatchResult
 }
@@ -136,14 +202,33 @@ func (d *DeviceInfo) GetModel() string {
 	return d.Model
 }
 
+// Model code reported by the device parser: the same as GetModel unless
+// the model has been renamed to its marketing name
+func (d *DeviceInfo) GetRawModel() string {
+	if d.RawModel != "" {
+		return d.RawModel
+	}
+	return d.Model
+}
+
 func (d *DeviceInfo) GetUserAgent() string {
 	return d.userAgent
 }
 
+// User agent the device and os were detected from, when forwarded by a
+// proxy browser in a header, empty otherwise
+func (d *DeviceInfo) GetDeviceUserAgent() string {
+	return d.deviceUserAgent
+}
+
 func (d *DeviceInfo) GetBot() *parser.BotMatchResult {
 	return d.bot
 }
 
+func (d *DeviceInfo) GetScanner() *parser.ScannerMatchResult {
+	return d.scanner
+}
+
 func (d *DeviceInfo) GetOsFamily() string {
 	if d.os != nil {
 		return parser.GetOsFamily(d.os.ShortName)
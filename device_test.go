@@ -1,6 +1,9 @@
 package devicedetector
 
 import (
+	"io/fs"
+	"net/http"
+	"os"
 	"strconv"
 	"testing"
 
@@ -92,6 +95,292 @@ func TestBot(t *testing.T) {
 	}
 }
 
+func TestAICrawler(t *testing.T) {
+	parser.ResetParserAbstract()
+
+	info := dd.Parse(`Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; GPTBot/1.1; +https://openai.com/gptbot)`)
+	require.True(t, info.IsAICrawler())
+	require.Equal(t, parser.BotPurposeTraining, info.GetBot().Purpose)
+	require.Equal(t, "OpenAI", info.GetBot().Producer.Name)
+	require.Equal(t, []string{"GPTBot"}, info.GetBot().RobotsTokens)
+
+	info = dd.Parse(`Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; PerplexityBot/1.0; +https://perplexity.ai/perplexitybot)`)
+	require.True(t, info.IsAICrawler())
+	require.Equal(t, parser.BotCategoryAIRetrieval, info.GetBot().Category)
+
+	info = dd.Parse(`Googlebot/2.1 (http://www.googlebot.com/bot.html)`)
+	require.True(t, info.IsBot())
+	require.False(t, info.IsAICrawler())
+	require.Equal(t, []string{"Googlebot"}, info.GetBot().RobotsTokens)
+
+	// the other Google crawlers come with their own tokens
+	for ua, tokens := range map[string][]string{
+		`AdsBot-Google (+http://www.google.com/adsbot.html)`:                                  {"AdsBot-Google", "AdsBot-Google-Mobile"},
+		`Mozilla/5.0 (compatible; Mediapartners-Google/2.1; +http://www.google.com/bot.html)`: {"Mediapartners-Google"},
+		`Feedfetcher-Google; (+http://www.google.com/feedfetcher.html; 2 subscribers)`:        {"Feedfetcher-Google"},
+	} {
+		require.Equal(t, tokens, dd.Parse(ua).GetBot().RobotsTokens, ua)
+	}
+}
+
+func TestAutomation(t *testing.T) {
+	parser.ResetParserAbstract()
+
+	info := dd.Parse(`Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/120.0.6099.109 Safari/537.36`)
+	require.True(t, info.IsAutomated())
+	require.Equal(t, parser.AutomationHeadless, info.GetAutomation().Type)
+	require.Equal(t, "Headless Chrome", info.GetClient().Name)
+
+	info = dd.Parse(`curl/7.88.1`)
+	require.True(t, info.IsAutomated())
+	require.Equal(t, &parser.AutomationMatchResult{
+		Type:    parser.AutomationHttpLibrary,
+		Name:    "curl",
+		Version: "7.88.1",
+	}, info.GetAutomation())
+
+	info = dd.Parse(`Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36`)
+	require.False(t, info.IsAutomated())
+	require.Equal(t, parser.AutomationNone, info.GetAutomation().Type)
+
+	dd.SkipAutomationDetection = true
+	defer func() { dd.SkipAutomationDetection = false }()
+	require.False(t, dd.Parse(`curl/7.88.1`).IsAutomated())
+}
+
+func TestScanner(t *testing.T) {
+	parser.ResetParserAbstract()
+
+	ua := `sqlmap/1.7.2#stable (https://sqlmap.org)`
+	require.True(t, dd.IsScanner(ua))
+	info := dd.Parse(ua)
+	require.True(t, info.IsScanner())
+	require.Equal(t, parser.ScannerCategoryInjection, info.GetScanner().Category)
+	require.Equal(t, parser.SeverityHigh, info.GetScanner().Severity)
+
+	// scanners also listed as bots are reported both ways
+	info = dd.Parse(`Arachni/v1.5.1`)
+	require.True(t, info.IsScanner())
+	require.True(t, info.IsBot())
+
+	ua = `Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36`
+	require.False(t, dd.IsScanner(ua))
+	require.False(t, dd.Parse(ua).IsScanner())
+
+	dd.SkipScannerDetection = true
+	defer func() { dd.SkipScannerDetection = false }()
+	require.False(t, dd.Parse(`sqlmap/1.7.2#stable (https://sqlmap.org)`).IsScanner())
+}
+
+func TestIabBotParser(t *testing.T) {
+	parser.ResetParserAbstract()
+
+	d, err := NewDeviceDetector("regexes", false)
+	require.NoError(t, err)
+	iab, err := parser.NewIabBot(nil, "parser/fixtures/iab_spiders.txt", "parser/fixtures/iab_browsers.txt")
+	require.NoError(t, err)
+	d.AddBotParser(iab)
+
+	// the bundled rules come first
+	require.Equal(t, "Googlebot", d.Parse(`Googlebot/2.1 (http://www.googlebot.com/bot.html)`).GetBot().Name)
+	info := d.Parse(`Java/1.8.0_151`)
+	require.True(t, info.IsBot())
+	require.Equal(t, parser.BotCategoryIab, info.GetBot().Category)
+}
+
+func TestParseWithHeaders(t *testing.T) {
+	parser.ResetParserAbstract()
+
+	proxyUA := `Opera/9.80 (Android; Opera Mini/36.2.2254/119.132; U; id) Presto/2.12.423 Version/12.16`
+	deviceUA := `Mozilla/5.0 (Linux; Android 8.1.0; SM-J260F Build/M1AJB) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/67.0.3396.87 Mobile Safari/537.36`
+	for _, name := range DeviceUserAgentHeaders {
+		header := http.Header{}
+		header.Set(name, deviceUA)
+		info := dd.ParseWithHeaders(proxyUA, header)
+		require.Equal(t, "Opera Mini", info.GetClient().Name, name)
+		require.Equal(t, "8.1.0", info.GetOs().Version, name)
+		require.Equal(t, "SA", info.GetBrand(), name)
+		require.Equal(t, "smartphone", info.GetDeviceName(), name)
+		require.Equal(t, proxyUA, info.GetUserAgent())
+		require.Equal(t, deviceUA, info.GetDeviceUserAgent())
+	}
+
+	// the cached result of the proxy user agent is left alone
+	info := dd.ParseWithHeaders(proxyUA, http.Header{})
+	require.Equal(t, "", info.GetBrand())
+	require.Equal(t, "", info.GetDeviceUserAgent())
+}
+
+func TestParseWithRequestedWith(t *testing.T) {
+	webviewUA := `Mozilla/5.0 (Linux; Android 12; SM-G991B Build/SP1A.210812.016; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/108.0.5359.128 Mobile Safari/537.36`
+
+	header := http.Header{}
+	header.Set(client.HeaderRequestedWith, "com.linkedin.android")
+	info := dd.ParseWithHeaders(webviewUA, header)
+	require.Equal(t, client.ParserNameMobileApp, info.GetClient().Type)
+	require.Equal(t, "LinkedIn", info.GetClient().Name)
+	require.Equal(t, "LIN", info.GetClient().ShortName)
+	require.Equal(t, "", info.GetBrowserFamily())
+	require.Equal(t, "SM-G991B", info.Model)
+
+	// browser packages are reported as the browser
+	header.Set(client.HeaderRequestedWith, "com.sec.android.app.sbrowser")
+	info = dd.ParseWithHeaders(webviewUA, header)
+	require.Equal(t, client.ParserNameBrowser, info.GetClient().Type)
+	require.Equal(t, "Samsung Browser", info.GetClient().Name)
+	require.Equal(t, "", info.GetClient().Version)
+	require.Equal(t, "Blink", info.GetClient().Engine)
+
+	// with the version of the client hints
+	header.Set("Sec-CH-UA-Full-Version-List", `"Not_A Brand";v="8.0.0.0", "Chromium";v="120.0.6099.230", "Samsung Internet";v="23.0.1.1"`)
+	info = dd.ParseWithHeaders(webviewUA, header)
+	require.Equal(t, "23.0.1.1", info.GetClient().Version)
+	header.Set(client.HeaderRequestedWith, "com.android.chrome")
+	require.Equal(t, "", dd.ParseWithHeaders(webviewUA, header).GetClient().Version)
+	header.Set("Sec-CH-UA-Full-Version-List", `"Google Chrome";v="120.0.6099.230", "Chromium";v="120.0.6099.230"`)
+	require.Equal(t, "120.0.6099.230", dd.ParseWithHeaders(webviewUA, header).GetClient().Version)
+	header.Del("Sec-CH-UA-Full-Version-List")
+
+	// the version of the app is kept when the user agent reports it
+	header.Set(client.HeaderRequestedWith, "com.facebook.katana")
+	info = dd.ParseWithHeaders(webviewUA+` [FB_IAB/FB4A;FBAV/397.0.0.23.404;]`, header)
+	require.Equal(t, "Facebook", info.GetClient().Name)
+	require.Equal(t, "397.0.0.23.404", info.GetClient().Version)
+
+	for _, v := range []string{"XMLHttpRequest", "com.example.unknown"} {
+		header.Set(client.HeaderRequestedWith, v)
+		info = dd.ParseWithHeaders(webviewUA, header)
+		require.Equal(t, "Chrome Webview", info.GetClient().Name, v)
+	}
+}
+
+func TestServerRendered(t *testing.T) {
+	data := []struct {
+		ua     string
+		vendor string
+		mode   string
+	}{
+		{`Opera/9.80 (Android; Opera Mini/36.2.2254/119.132; U; id) Presto/2.12.423 Version/12.16`, "Opera", client.ProxyModeServerRendered},
+		{`Mozilla/5.0 (Linux; U; Android 7.1.1; en-US; SM-J510H Build/NMF26X) AppleWebKit/534.30 (KHTML, like Gecko) Version/4.0 UCBrowser/11.0.5.841 (UCMini) Mobile Safari/534.30`, "UCWeb", client.ProxyModeServerRendered},
+		{`Mozilla/5.0 (iPad; CPU OS 11_2_5 like Mac OS X) AppleWebKit/604.5.6 (KHTML, like Gecko) Mobile/15D60 Puffin/5.2.2IP`, "CloudMosa", client.ProxyModeServerRendered},
+		{`Mozilla/5.0 (Linux; Android 4.2.1; en-us; Nexus 5 Build/JOP40D) AppleWebKit/535.19 (KHTML, like Gecko; googleweblight) Chrome/38.0.1025.166 Mobile Safari/535.19`, "Google", client.ProxyModeTranscoded},
+		{`Mozilla/5.0 (Linux; U; en-gb; KFSOWI Build/JDQ39) AppleWebKit/535.19 (KHTML, like Gecko) Silk/3.12 Safari/535.19 Silk-Accelerated=true`, "Amazon", client.ProxyModeServerRendered},
+		{`Mozilla/5.0 (Linux; U; Android 4.0.3; en-us; KFTT Build/IML74K) AppleWebKit/535.19 (KHTML, like Gecko) Silk/3.4 Mobile Safari/535.19 Silk-Accelerated=false`, "", ""},
+		{`Mozilla/5.0 (Linux; Android 4.4.2; Nexus 4 Build/KOT49H) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/33.0.1750.136 Mobile Safari/537.36`, "", ""},
+	}
+	for _, item := range data {
+		info := dd.Parse(item.ua)
+		require.Equal(t, item.mode != "", info.IsServerRendered(), item.ua)
+		require.Equal(t, item.vendor, info.GetClient().ProxyVendor, item.ua)
+		require.Equal(t, item.mode, info.GetClient().ProxyMode, item.ua)
+	}
+}
+
+func TestInAppBrowser(t *testing.T) {
+	info := dd.Parse(`Mozilla/5.0 (iPhone; CPU iPhone OS 16_1_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 [FBAN/FBIOS;FBAV/395.0.0.36.107;FBBV/445009853;FBDV/iPhone14,2;FBMD/iPhone;FBSN/iOS;FBSV/16.1.1;FBSS/3;FBID/phone;FBLC/en_US;FBOP/5]`)
+	require.True(t, info.IsInAppBrowser())
+	require.Equal(t, "Facebook", info.GetInAppBrowser().App)
+	require.Equal(t, "395.0.0.36.107", info.GetInAppBrowser().AppVersion)
+	require.Equal(t, "WebKit", info.GetInAppBrowser().Engine)
+
+	// reported whichever client the useragent is classified as
+	info = dd.Parse(`Mozilla/5.0 (Linux; Android 12; SM-G991B Build/SP1A.210812.016; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/108.0.5359.128 Mobile Safari/537.36 [LinkedInApp]/4.1.815`)
+	require.True(t, info.IsInAppBrowser())
+	require.Equal(t, "LinkedIn", info.GetInAppBrowser().App)
+	require.Equal(t, info.GetClient().Name, info.GetInAppBrowser().Browser)
+
+	// requests of the app itself
+	info = dd.Parse(`Instagram 219.0.0.12.117 Android (30/11; 420dpi; 1080x2201; samsung; SM-A515F; a51; exynos9611; en_US; 346138365)`)
+	require.False(t, info.IsInAppBrowser())
+	require.Equal(t, "", info.GetInAppBrowser().App)
+
+	info = dd.Parse(`Mozilla/5.0 (Linux; Android 4.4.2; Nexus 4 Build/KOT49H) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/33.0.1750.136 Mobile Safari/537.36`)
+	require.False(t, info.IsInAppBrowser())
+
+	dd.SkipInAppBrowserDetection = true
+	defer func() { dd.SkipInAppBrowserDetection = false }()
+	info = dd.Parse(`Mozilla/5.0 (Linux; Android 12; SM-G991B Build/SP1A.210812.016; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/108.0.5359.128 Mobile Safari/537.36 [LinkedInApp]/4.1.815`)
+	require.False(t, info.IsInAppBrowser())
+}
+
+func TestWebView(t *testing.T) {
+	data := map[string]string{
+		`Mozilla/5.0 (Linux; Android 12; SM-G991B Build/SP1A.210812.016; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/108.0.5359.128 Mobile Safari/537.36`: client.WebViewAndroid,
+		`Mozilla/5.0 (iPhone; CPU iPhone OS 16_1_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148`:                                                   client.WebViewIos,
+		`Mozilla/5.0 (Linux; Android 12; SM-G991B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/108.0.5359.128 Mobile Safari/537.36`:                                       client.WebViewNone,
+		`Mozilla/5.0 (iPhone; CPU iPhone OS 16_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.1 Mobile/15E148 Safari/604.1`:                           client.WebViewNone,
+	}
+	for ua, kind := range data {
+		info := dd.Parse(ua)
+		require.Equal(t, kind != client.WebViewNone, info.IsWebView(), ua)
+		require.Equal(t, kind, info.GetWebViewKind(), ua)
+	}
+
+	dd.SkipWebViewDetection = true
+	defer func() { dd.SkipWebViewDetection = false }()
+	require.False(t, dd.Parse(`Mozilla/5.0 (iPhone; CPU iPhone OS 16_1_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148`).IsWebView())
+}
+
+func TestDarwinApp(t *testing.T) {
+	data := []struct {
+		ua         string
+		app        string
+		appVersion string
+		os         string
+		osVersion  string
+		deviceType string
+	}{
+		{`MyApp/3.2 CFNetwork/1408.0.4 Darwin/22.5.0`, "MyApp", "3.2", "iOS", "16.5", ""},
+		{`MyApp/3.2 (iPhone) CFNetwork/1408.0.4 Darwin/22.5.0`, "MyApp", "3.2", "iOS", "16.5", "smartphone"},
+		{`Slack/23.05.10 CFNetwork/1408.0.4 Darwin/22.5.0 (iPad)`, "Slack", "23.05.10", "iOS", "16.5", "tablet"},
+		{`MyApp/1.0 CFNetwork/1335.0.3 Darwin/21.6.0 (x86_64)`, "MyApp", "1.0", "Mac", "12.5", "desktop"},
+	}
+	for _, item := range data {
+		info := dd.Parse(item.ua)
+		require.Equal(t, client.ParserNameMobileApp, info.GetClient().Type, item.ua)
+		require.Equal(t, item.app, info.GetClient().Name, item.ua)
+		require.Equal(t, item.appVersion, info.GetClient().Version, item.ua)
+		require.Equal(t, item.os, info.GetOs().Name, item.ua)
+		require.Equal(t, item.osVersion, info.GetOs().Version, item.ua)
+		require.Equal(t, item.deviceType, info.GetDeviceName(), item.ua)
+		require.Equal(t, "AP", info.GetBrand(), item.ua)
+	}
+}
+
+func TestAppleModel(t *testing.T) {
+	data := []struct {
+		ua         string
+		model      string
+		rawModel   string
+		deviceType string
+	}{
+		{`Mozilla/5.0 (iPhone; CPU iPhone OS 16_1_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 [FBAN/FBIOS;FBAV/395.0.0.36.107;FBDV/iPhone14,2;FBMD/iPhone;FBSN/iOS;FBSV/16.1.1]`, "iPhone 13 Pro", "iPhone14,2", "phablet"},
+		{`MyApp/3.2 (iPad13,4; iPadOS 16.5; Scale/2.00) CFNetwork/1408.0.4 Darwin/22.5.0`, "iPad Pro 5 11.0", "iPad13,4", "tablet"},
+		{`Strava/295.0.0 (Watch6,1; watchOS 9.1) Alamofire/5.6.2`, "Apple Watch Series 6", "Watch6,1", "wearable"},
+		// unknown identifiers fall back to the device regexes
+		{`MyApp/1.0 (iPhone99,1; iOS 30.0)`, "iPhone", "iPhone", "smartphone"},
+		{`Apple-iPhone5C2/1001.525`, "iPhone 5", "iPhone5,2", "smartphone"},
+	}
+	for _, item := range data {
+		info := dd.Parse(item.ua)
+		require.Equal(t, "AP", info.GetBrand(), item.ua)
+		require.Equal(t, item.model, info.GetModel(), item.ua)
+		require.Equal(t, item.rawModel, info.GetRawModel(), item.ua)
+		require.Equal(t, item.deviceType, info.GetDeviceName(), item.ua)
+	}
+}
+
+func TestCompatibilityMode(t *testing.T) {
+	info := dd.Parse(`Mozilla/4.0 (compatible; MSIE 7.0; Windows NT 10.0; WOW64; Trident/7.0; .NET4.0C; .NET4.0E)`)
+	require.Equal(t, "Internet Explorer", info.GetClient().Name)
+	require.Equal(t, "11.0", info.GetClient().Version)
+	require.True(t, info.GetClient().CompatibilityMode)
+
+	info = dd.Parse(`Mozilla/4.0 (compatible; MSIE 7.0; Windows NT 5.1)`)
+	require.Equal(t, "7.0", info.GetClient().Version)
+	require.False(t, info.GetClient().CompatibilityMode)
+}
+
 func TestTypeMethods(t *testing.T) {
 	parser.ResetParserAbstract()
 
@@ -182,6 +471,22 @@ type SmartFixture struct {
 	BrowserFamily string                    `yaml:"browser_family"`
 }
 
+func TestMediaPlayer(t *testing.T) {
+	parser.ResetParserAbstract()
+
+	var list []struct {
+		UserAgent string                    `yaml:"user_agent"`
+		Client    *client.ClientMatchResult `yaml:"client"`
+	}
+	require.NoError(t, parser.ReadYamlFile(`fixtures/mediaplayer.yml`, &list))
+	for _, f := range list {
+		c := dd.Parse(f.UserAgent).GetClient()
+		require.Equal(t, f.Client.Type, c.Type, f.UserAgent)
+		require.Equal(t, f.Client.Name, c.Name, f.UserAgent)
+		require.Equal(t, f.Client.Version, c.Version, f.UserAgent)
+	}
+}
+
 func TestRegThread(t *testing.T) {
 	parser.ResetParserAbstract()
 
@@ -218,3 +523,199 @@ func TestRegThread(t *testing.T) {
 	}
 
 }
+
+func TestOverlay(t *testing.T) {
+	parser.ResetParserAbstract()
+
+	od, err := NewDeviceDetector("regexes", false, "fixtures/overlay")
+	require.NoError(t, err)
+
+	info := od.Parse(`AcmeMonitor/1.2 (+https://acme.example/monitor)`)
+	require.True(t, info.IsBot())
+	require.Equal(t, `Acme Monitor`, info.GetBot().Name)
+
+	info = od.Parse(`Googlebot/2.1 (http://www.googlebot.com/bot.html)`)
+	require.NotEqual(t, `Googlebot`, info.GetBot().Name)
+
+	info = od.Parse(`Mozilla/5.0 (Linux; Android 10; Pixel 3) AppleWebKit/537.36 (KHTML, like Gecko) AcmeApp/2.3 Mobile Safari/537.36`)
+	require.Equal(t, `mobile app`, info.GetClient().Type)
+	require.Equal(t, `Acme App`, info.GetClient().Name)
+	require.Equal(t, `2.3`, info.GetClient().Version)
+
+	info = od.Parse(`Mozilla/5.0 (Linux; Android 4.2.2; ARCHOS 101 PLATINUM Build/JDQ39) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/34.0.1847.114 Safari/537.36`)
+	require.Equal(t, `Archos`, info.GetBrandName())
+	require.Equal(t, `101 Platinum`, info.GetModel())
+
+	info = od.Parse(`Mozilla/5.0 (Linux; Android 9; AcmeScreen 55) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/80.0.3987.87 Safari/537.36`)
+	require.Equal(t, `Vestel`, info.GetBrandName())
+	require.Equal(t, `AcmeScreen 55`, info.GetModel())
+	require.Equal(t, `tv`, info.GetDeviceName())
+
+	// bundled rules are still there
+	info = od.Parse(`Mozilla/5.0 (Linux; Android 4.4.2; Nexus 4 Build/KOT49H) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/33.0.1750.136 Mobile Safari/537.36`)
+	require.Equal(t, `Google`, info.GetBrandName())
+
+	_, err = NewDeviceDetector("regexes", false, "fixtures/missing-overlay")
+	require.Error(t, err)
+}
+
+func TestAddRules(t *testing.T) {
+	parser.ResetParserAbstract()
+
+	rd, err := NewDeviceDetector("regexes", true)
+	require.NoError(t, err)
+
+	ua := `Mozilla/5.0 (Linux; Android 10; AcmePad 7) AppleWebKit/537.36 (KHTML, like Gecko) AcmeBrowser/4.1 AcmeOS/2.0 Safari/537.36`
+	require.Equal(t, `Android`, rd.Parse(ua).GetOs().Name)
+
+	require.NoError(t, rd.AddOsRule(`AcmeOS/(\d+[\.\d]+)`, `Tizen`, `$1`))
+	require.NoError(t, rd.AddBrowserRule(`AcmeBrowser/(\d+[\.\d]+)`, `Kiwi`, `$1`))
+	require.NoError(t, rd.AddDeviceRule(`Vestel`, `AcmePad`,
+		&device.Model{Regular: parser.Regular{Regex: `AcmePad (\d+)`}, Model: `AcmePad $1`, Device: `tablet`}))
+	require.NoError(t, rd.AddBotRule(`AcmeMonitor`, parser.BotMatchResult{Name: `Acme Monitor`, Category: `Site Monitor`}))
+
+	info := rd.Parse(ua)
+	require.Equal(t, `Tizen`, info.GetOs().Name)
+	require.Equal(t, `2.0`, info.GetOs().Version)
+	require.Equal(t, `Kiwi`, info.GetClient().Name)
+	require.Equal(t, `KW`, info.GetClient().ShortName)
+	require.Equal(t, `4.1`, info.GetClient().Version)
+	require.Equal(t, `Vestel`, info.GetBrandName())
+	require.Equal(t, `AcmePad 7`, info.GetModel())
+	require.Equal(t, `tablet`, info.GetDeviceName())
+
+	info = rd.Parse(`AcmeMonitor/1.0`)
+	require.Equal(t, `Acme Monitor`, info.GetBot().Name)
+
+	require.Error(t, rd.AddBotRule(`Acme(`, parser.BotMatchResult{Name: `Acme`}))
+	require.Error(t, rd.AddBrowserRule(`Acme`, `Not A Browser`, ``))
+	require.Error(t, rd.AddBotRule(`AcmeCrawler`, parser.BotMatchResult{}))
+	require.Error(t, rd.AddDeviceRule(`Not A Brand`, `Acme`))
+	require.Error(t, rd.AddDeviceRule(`Vestel`, `Acme`,
+		&device.Model{Regular: parser.Regular{Regex: `Acme`}, Model: `Acme`, Device: `hoverboard`}))
+
+	// the bundled Vestel entry is left untouched
+	info = rd.Parse(`Mozilla/5.0 (Linux; Android 4.3; VSP145M Build/JLS36C) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/47.0.2526.83 Mobile Safari/537.36`)
+	require.Equal(t, `Vestel`, info.GetBrandName())
+	require.Equal(t, `smartphone`, info.GetDeviceName())
+}
+
+func TestGenericBot(t *testing.T) {
+	parser.ResetParserAbstract()
+
+	d, err := NewDeviceDetector("regexes", false)
+	require.NoError(t, err)
+	for _, ua := range []string{
+		`FooCrawler/1.0 (+http://foo.example/bot)`,
+		`Mozilla/5.0 (compatible; Foo/1.0; +http://foo.example/)`,
+		// bundled entry flagged generic
+		`htdig/3.1.6 (root@localhost)`,
+	} {
+		info := d.Parse(ua)
+		require.True(t, info.IsBot(), ua)
+		require.True(t, info.GetBot().Generic, ua)
+	}
+
+	// apps linking to their site
+	info := d.Parse(`gPodder/3.5.2 (+http://gpodder.org/)`)
+	require.False(t, info.IsBot())
+	require.Equal(t, "gPodder", info.GetClient().Name)
+
+	// phones named like bots
+	for _, ua := range []string{
+		`Mozilla/5.0 (Linux; Android 9; CUBOT X19) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/80.0.3987.99 Mobile Safari/537.36`,
+		`Mozilla/5.0 (Linux; Android 6.0; POWER BOT Build/MRA58K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/66.0.3359.158 Mobile Safari/537.36`,
+		`Mozilla/5.0 (Linux; Android 10; M BOT 51) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/88.0.4324.181 Mobile Safari/537.36`,
+	} {
+		require.False(t, d.Parse(ua).IsBot(), ua)
+	}
+
+	// every caller gets its own result
+	first := d.Parse(`FooCrawler/1.0`).GetBot()
+	first.Name = "Foo"
+	require.Equal(t, "Generic Bot", d.Parse(`FooCrawler/1.0`).GetBot().Name)
+
+	info = d.Parse(`Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)`)
+	require.Equal(t, "Googlebot", info.GetBot().Name)
+	require.False(t, info.GetBot().Generic)
+
+	d.SkipGenericBotDetection = true
+	for _, ua := range []string{
+		`FooCrawler/1.0 (+http://foo.example/bot)`,
+		`Mozilla/5.0 (compatible; Foo/1.0; +http://foo.example/)`,
+		`htdig/3.1.6 (root@localhost)`,
+	} {
+		require.False(t, d.Parse(ua).IsBot(), ua)
+	}
+}
+
+// the regexes of the repository without the files of hidden
+type hiddenFS struct {
+	fs.FS
+	hidden []string
+}
+
+func (h hiddenFS) Open(name string) (fs.File, error) {
+	for _, file := range h.hidden {
+		if name == file {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+	}
+	return h.FS.Open(name)
+}
+
+func TestOptionalTables(t *testing.T) {
+	parser.ResetParserAbstract()
+
+	load := func(hidden ...string) *DeviceDetector {
+		d, err := NewDeviceDetectorFS(hiddenFS{os.DirFS("regexes"), hidden}, ".", false)
+		require.NoError(t, err, hidden)
+		require.NoError(t, d.ApplyOverlay("fixtures/overlay"), hidden)
+		return d
+	}
+
+	d := load(parser.FixtureFileAutomation)
+	info := d.Parse(`Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/120.0.6099.109 Safari/537.36`)
+	require.False(t, info.IsAutomated())
+	require.True(t, d.Parse(`curl/7.88.1`).IsAutomated())
+
+	d = load(parser.FixtureFileScanner)
+	require.False(t, d.IsScanner(`sqlmap/1.7.2#stable (https://sqlmap.org)`))
+	require.False(t, d.Parse(`sqlmap/1.7.2#stable (https://sqlmap.org)`).IsScanner())
+
+	d = load("client/" + client.FixtureFileInAppBrowser)
+	info = d.Parse(`Mozilla/5.0 (iPhone; CPU iPhone OS 16_1_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 [FBAN/FBIOS;FBAV/395.0.0.36.107;FBBV/445009853;FBDV/iPhone14,2;FBMD/iPhone;FBSN/iOS;FBSV/16.1.1;FBSS/3;FBID/phone;FBLC/en_US;FBOP/5]`)
+	require.False(t, info.IsInAppBrowser())
+	require.Equal(t, `iOS`, info.GetOs().Name)
+
+	d = load("client/" + client.FixtureFileWebView)
+	info = d.Parse(`Mozilla/5.0 (Linux; Android 12; SM-G991B Build/SP1A.210812.016; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/108.0.5359.128 Mobile Safari/537.36`)
+	require.False(t, info.IsWebView())
+	require.Equal(t, client.WebViewNone, info.GetWebViewKind())
+
+	d = load("client/" + client.FixtureFileAppHints)
+	header := http.Header{}
+	header.Set(client.HeaderRequestedWith, "com.linkedin.android")
+	info = d.ParseWithHeaders(`Mozilla/5.0 (Linux; Android 12; SM-G991B Build/SP1A.210812.016; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/108.0.5359.128 Mobile Safari/537.36`, header)
+	require.Equal(t, "Chrome Webview", info.GetClient().Name)
+	require.Nil(t, d.ParseAppHint("com.linkedin.android"))
+
+	d = load(parser.FixtureFileDarwin)
+	require.Equal(t, "", d.Parse(`MyApp/3.2 CFNetwork/1408.0.4 Darwin/22.5.0`).GetOs().Version)
+	require.NoError(t, d.AddOsRule(`AcmeOS/(\d+[\.\d]+)`, `GNU/Linux`, `$1`))
+
+	d = load("device/" + device.FixtureFileModelAlias)
+	d.NormalizeModels = true
+	require.Equal(t, `SM-G991B`, d.Parse(`Mozilla/5.0 (Linux; Android 12; SM-G991B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/108.0.5359.128 Mobile Safari/537.36`).GetModel())
+
+	ua := `Mozilla/5.0 (iPhone; CPU iPhone OS like Mac OS X) AppleWebKit/601.1.46 (KHTML, like Gecko) Mobile/13G36 Safari/601.1`
+	info = dd.Parse(ua)
+	require.Equal(t, `9.3.5`, info.GetOs().Version)
+	require.Equal(t, `9.0`, info.GetClient().Version)
+	d = load(parser.FixtureFileIosBuild, "client/"+client.FixtureFileWebKitVersion)
+	info = d.Parse(ua)
+	require.Equal(t, `iOS`, info.GetOs().Name)
+	require.Equal(t, ``, info.GetOs().Version)
+	require.Equal(t, `Mobile Safari`, info.GetClient().Name)
+	require.Equal(t, ``, info.GetClient().Version)
+}
@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"io/fs"
+	"strings"
+)
+
+const ParserNameAutomation = "automation"
+const FixtureFileAutomation = "automation.yml"
+
+// How a client is automated
+const (
+	AutomationNone        = ""
+	AutomationHeadless    = "headless"     // browser without a display
+	AutomationDriver      = "driver"       // framework driving a browser
+	AutomationHttpLibrary = "http library" // HTTP client of a programming language
+)
+
+type AutomationReg struct {
+	Regular  `yaml:",inline" json:",inline"`
+	Name     string `yaml:"name" json:"name"`
+	Type     string `yaml:"type" json:"type"`
+	Version  string `yaml:"version" json:"version"`
+	Disabled bool   `yaml:"disabled" json:"disabled"`
+}
+
+type AutomationMatchResult struct {
+	Type    string `yaml:"type" json:"type"`
+	Name    string `yaml:"name" json:"name"`
+	Version string `yaml:"version" json:"version"`
+}
+
+// Parses the useragent for headless browsers and automation frameworks
+type Automation struct {
+	Regexes      []*AutomationReg
+	file         string
+	overAllMatch Regular
+}
+
+func NewAutomation(fsys fs.FS, file string) (*Automation, error) {
+	var v []*AutomationReg
+	err := ReadYamlFS(fsys, file, &v)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range v {
+		item.Compile()
+	}
+	return &Automation{
+		Regexes: v,
+		file:    file,
+	}, nil
+}
+
+func (a *Automation) ApplyOverlay(dir string) error {
+	regexes, err := MergeOverlay(dir, a.file, a.Regexes,
+		func(item *AutomationReg) (string, bool) { return item.Name, item.Disabled },
+		(*AutomationReg).Validate)
+	if err != nil {
+		return err
+	}
+	a.Regexes = regexes
+	a.overAllMatch = Regular{}
+	return nil
+}
+
+func (a *Automation) PreMatch(ua string) bool {
+	if a.overAllMatch.Regexp == nil {
+		count := len(a.Regexes)
+		if count == 0 {
+			return false
+		}
+		sb := strings.Builder{}
+		sb.WriteString(a.Regexes[count-1].Regex)
+		for i := count - 2; i >= 0; i-- {
+			sb.WriteString("|")
+			sb.WriteString(a.Regexes[i].Regex)
+		}
+		a.overAllMatch.Regex = sb.String()
+		a.overAllMatch.Compile()
+	}
+	return a.overAllMatch.IsMatchUserAgent(ua)
+}
+
+func (a *Automation) Parse(ua string) *AutomationMatchResult {
+	if !a.PreMatch(ua) {
+		return nil
+	}
+	for _, regex := range a.Regexes {
+		matches := regex.MatchUserAgent(ua)
+		if len(matches) > 0 {
+			return &AutomationMatchResult{
+				Type:    regex.Type,
+				Name:    BuildByMatch(regex.Name, matches),
+				Version: BuildVersion(regex.Version, matches),
+			}
+		}
+	}
+	return nil
+}
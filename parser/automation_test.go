@@ -0,0 +1,32 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutomationParse(t *testing.T) {
+	type AutomationFixture struct {
+		AutomationMatchResult `yaml:"automation" json:"automation"`
+		UserAgent             string `yaml:"user_agent" json:"user_agent"`
+	}
+
+	automationParser, err := NewAutomation(nil, filepath.Join(dir, FixtureFileAutomation))
+	require.NoError(t, err)
+
+	var list []AutomationFixture
+	err = ReadYamlFile(`fixtures/automation.yml`, &list)
+	if err != nil {
+		t.Error(err)
+	}
+
+	for _, item := range list {
+		r := automationParser.Parse(item.UserAgent)
+		require.EqualValues(t, &item.AutomationMatchResult, r, item.UserAgent)
+	}
+
+	r := automationParser.Parse(`Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36`)
+	require.Nil(t, r)
+}
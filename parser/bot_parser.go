@@ -1,17 +1,54 @@
 package parser
 
-import "strings"
+import (
+	"errors"
+	"io/fs"
+	"strings"
+)
 
 type Producer struct {
 	Name string `yaml:"name" json:"name"`
 	Url  string `yaml:"url" json:"url"`
 }
 
+// Published information allowing to check that a request claiming to come
+// from a bot really originates from its operator
+type BotVerification struct {
+	// Suffixes of the hostnames the bot IPs resolve to, e.g. googlebot.com
+	Hostnames []string `yaml:"hostnames" json:"hostnames"`
+	// Files listing the IP ranges of the bot, one CIDR per line
+	IPRanges []string `yaml:"ip_ranges" json:"ip_ranges"`
+}
+
+// Categories of the crawlers feeding AI models
+const (
+	BotCategoryAITraining  = "AI Training Crawler"
+	BotCategoryAIRetrieval = "AI Retrieval Crawler"
+)
+
+// What a bot does with the fetched content
+const (
+	BotPurposeTraining  = "training"   // model training datasets
+	BotPurposeSearch    = "search"     // index behind AI search answers
+	BotPurposeUserFetch = "user-fetch" // fetched live for a user prompt
+)
+
 type BotMatchResult struct {
-	Name     string   `yaml:"name" json:"name"`
-	Category string   `yaml:"category" json:"category"`
-	Url      string   `yaml:"url" json:"url"`
+	Name     string `yaml:"name" json:"name"`
+	Category string `yaml:"category" json:"category"`
+	Purpose  string `yaml:"purpose" json:"purpose,omitempty"`
+	Url      string `yaml:"url" json:"url"`
+	// Operator of the bot
 	Producer Producer `yaml:"producer" json:"producer"`
+	// robots.txt product tokens the bot honours
+	RobotsTokens []string         `yaml:"robots_tokens" json:"robots_tokens,omitempty"`
+	Verification *BotVerification `yaml:"verification" json:"verification,omitempty"`
+	// True when detected by a catch-all pattern rather than a specific rule
+	Generic bool `yaml:"generic" json:"generic,omitempty"`
+}
+
+func (b *BotMatchResult) IsAICrawler() bool {
+	return b.Category == BotCategoryAITraining || b.Category == BotCategoryAIRetrieval
 }
 
 func (b *BotMatchResult) Equal(a *BotMatchResult) bool {
@@ -25,6 +62,23 @@ func (b *BotMatchResult) Equal(a *BotMatchResult) bool {
 type BotReg struct {
 	Regular        `yaml:",inline" json:",inline"`
 	BotMatchResult `yaml:",inline" json:",inline"`
+	Disabled       bool `yaml:"disabled" json:"disabled"`
+}
+
+// Catch-all patterns recognizing the usual bot conventions: a bot, crawler
+// or spider product name, or a link to the bot information page in a
+// compatible comment. The phones named like bots (CUBOT, POWER BOT, M BOT
+// 51...) are left out, and so are the apps linking to their site
+// (gPodder/3.5.2 (+http://gpodder.org/))
+var DefaultGenericBotPatterns = []string{
+	`[a-z0-9\-_]*(?:(?<!cu|power[ _]|m[ _])bot(?![ _]TAB|[ _]?5[0-9])|crawler|crawl|checker|archiver|transcoder|spider|scraper)(?:[^a-z]|$)`,
+	`compatible;[^)]*\+https?://`,
+}
+
+// Result of the generic detection, ParseGeneric returns copies of it
+var GenericBotMatchResult = &BotMatchResult{
+	Name:    "Generic Bot",
+	Generic: true,
 }
 
 type BotParser interface {
@@ -33,11 +87,20 @@ type BotParser interface {
 	DiscardDetails(bool)
 }
 
+// Implemented by bot parsers offering a generic detection
+type GenericBotParser interface {
+	ParseGeneric(string) *BotMatchResult
+}
+
 // Abstract class for all bot parsers
 type BotParserAbstract struct {
-	Regexes        []*BotReg
-	ParserName     string
+	Regexes    []*BotReg
+	ParserName string
+	// Entries flagged generic, tried along with the catch-all patterns
+	GenericRegexes []*BotReg
+	file           string
 	discardDetails bool
+	generic        []*Regular
 	overAllMatch   Regular
 }
 
@@ -45,16 +108,101 @@ func (b *BotParserAbstract) DiscardDetails(v bool) {
 	b.discardDetails = v
 }
 
-func (b *BotParserAbstract) Load(file string) error {
+// Replace the catch-all patterns of the generic detection, an empty list
+// disables it
+func (b *BotParserAbstract) SetGenericPatterns(patterns []string) error {
+	generic := make([]*Regular, 0, len(patterns))
+	for _, pattern := range patterns {
+		r := &Regular{Regex: pattern}
+		if err := r.Validate(); err != nil {
+			return err
+		}
+		generic = append(generic, r)
+	}
+	b.generic = generic
+	return nil
+}
+
+// Match ua against the entries flagged generic and the catch-all patterns
+// only, meant to be called once Parse found nothing
+func (b *BotParserAbstract) ParseGeneric(ua string) *BotMatchResult {
+	for _, regex := range b.GenericRegexes {
+		if regex.IsMatchUserAgent(ua) {
+			if b.discardDetails {
+				return EmptyBotMatchResult
+			}
+			r := regex.BotMatchResult
+			return &r
+		}
+	}
+	for _, r := range b.generic {
+		if r.IsMatchUserAgent(ua) {
+			if b.discardDetails {
+				return EmptyBotMatchResult
+			}
+			r := *GenericBotMatchResult
+			return &r
+		}
+	}
+	return nil
+}
+
+func (b *BotParserAbstract) Load(fsys fs.FS, file string) error {
 	var v []*BotReg
-	err := ReadYamlFile(file, &v)
+	err := ReadYamlFS(fsys, file, &v)
 	if err != nil {
 		return err
 	}
+	b.Regexes, b.GenericRegexes = splitGenericBots(v)
 	for _, item := range v {
 		item.Compile()
 	}
-	b.Regexes = v
+	b.file = file
+	if b.generic == nil {
+		return b.SetGenericPatterns(DefaultGenericBotPatterns)
+	}
+	return nil
+}
+
+// Split the entries flagged generic from the specific ones
+func splitGenericBots(v []*BotReg) (specific, generic []*BotReg) {
+	for _, item := range v {
+		if item.Generic {
+			generic = append(generic, item)
+		} else {
+			specific = append(specific, item)
+		}
+	}
+	return specific, generic
+}
+
+func (b *BotParserAbstract) ApplyOverlay(dir string) error {
+	loaded := append(append([]*BotReg{}, b.Regexes...), b.GenericRegexes...)
+	regexes, err := MergeOverlay(dir, b.file, loaded,
+		func(item *BotReg) (string, bool) { return item.Name, item.Disabled },
+		(*BotReg).Validate)
+	if err != nil {
+		return err
+	}
+	b.Regexes, b.GenericRegexes = splitGenericBots(regexes)
+	b.overAllMatch = Regular{}
+	return nil
+}
+
+// Adds a rule ahead of the loaded ones
+func (b *BotParserAbstract) AddRule(regex string, r BotMatchResult) error {
+	if r.Name == "" {
+		return errors.New("empty bot name")
+	}
+	item := &BotReg{
+		Regular:        Regular{Regex: regex},
+		BotMatchResult: r,
+	}
+	if err := item.Validate(); err != nil {
+		return err
+	}
+	b.Regexes = append([]*BotReg{item}, b.Regexes...)
+	b.overAllMatch = Regular{}
 	return nil
 }
 
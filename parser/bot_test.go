@@ -7,7 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-var botParser = NewBot(filepath.Join(dir, FixtureFileBot))
+var botParser = NewBot(nil, filepath.Join(dir, FixtureFileBot))
 
 func TestGetInfoFromUABot(t *testing.T) {
 	ua := `Googlebot/2.1 (http://www.googlebot.com/bot.html)`
@@ -21,6 +21,11 @@ func TestGetInfoFromUABot(t *testing.T) {
 			Name: "Google Inc.",
 			Url:  "http://www.google.com",
 		},
+		RobotsTokens: []string{"Googlebot"},
+		Verification: &BotVerification{
+			Hostnames: []string{"googlebot.com"},
+			IPRanges:  []string{"googlebot.txt"},
+		},
 	}
 	require.EqualValues(t, expected, info)
 }
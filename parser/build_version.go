@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+const FixtureFileIosBuild = `ios_builds.yml`
+
+type BuildVersionItem struct {
+	// Build number, or the prefix shared by the builds of a release: 602.1
+	// for 602.1.50, 14E for 14E304
+	Build   string `yaml:"build" json:"build"`
+	Version string `yaml:"version" json:"version"`
+}
+
+// Table mapping the build numbers found in the useragents (AppleWebKit/,
+// Mobile/...) to the version of the release that shipped them
+type BuildVersions struct {
+	versions map[string]string
+	reg      Regular
+}
+
+// Load the table of file, the build being the first group of regex
+func NewBuildVersions(fsys fs.FS, file, regex string) (*BuildVersions, error) {
+	var v []*BuildVersionItem
+	if err := ReadYamlFS(fsys, file, &v); err != nil {
+		return nil, err
+	}
+	b := &BuildVersions{
+		versions: make(map[string]string, len(v)),
+		reg:      Regular{Regex: regex},
+	}
+	b.reg.Compile()
+	for _, item := range v {
+		if item.Build == "" || item.Version == "" {
+			return nil, fmt.Errorf("%s: empty build or version", file)
+		}
+		b.versions[item.Build] = item.Version
+	}
+	return b, nil
+}
+
+// Returns the version of the build found in ua, empty when there is none or
+// when it isn't in the table
+func (b *BuildVersions) Parse(ua string) string {
+	m := b.reg.MatchUserAgent(ua)
+	if len(m) < 2 {
+		return ""
+	}
+	return b.Lookup(m[1])
+}
+
+// Returns the version of build, looking for the build and then for its
+// prefixes: 602.1.50, 602.1 and 602, or 14E304 and 14E
+func (b *BuildVersions) Lookup(build string) string {
+	for key := build; key != ""; key = parentBuild(key) {
+		if v, ok := b.versions[key]; ok {
+			return BuildVersion(v, nil)
+		}
+	}
+	return ""
+}
+
+func parentBuild(build string) string {
+	if i := strings.LastIndexByte(build, '.'); i > 0 {
+		return build[:i]
+	}
+	i := strings.LastIndexFunc(build, func(r rune) bool { return r < '0' || r > '9' })
+	if i >= 0 && i < len(build)-1 {
+		return build[:i+1]
+	}
+	return ""
+}
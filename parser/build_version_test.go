@@ -0,0 +1,31 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildVersions(t *testing.T) {
+	builds, err := NewBuildVersions(nil, filepath.Join(dir, FixtureFileIosBuild), `Mobile/(\d+[A-Z]\d+)`)
+	require.NoError(t, err)
+
+	data := []struct {
+		build   string
+		version string
+	}{
+		{`14E304`, `10.3.1`},
+		{`14E277`, `10.3`},
+		{`13G36`, `9.3.5`},
+		{`9B176`, `5.1`},
+		// frozen since iOS 11
+		{`15E148`, ``},
+		{`14Z1`, ``},
+	}
+	for _, item := range data {
+		require.Equal(t, item.version, builds.Lookup(item.build), item.build)
+	}
+	require.Equal(t, `8.4`, builds.Parse(`Mozilla/5.0 (iPhone; CPU iPhone OS like Mac OS X) AppleWebKit/600.1.4 (KHTML, like Gecko) Mobile/12H143`))
+	require.Empty(t, builds.Parse(`Mozilla/5.0 (Windows NT 10.0; Win64; x64)`))
+}
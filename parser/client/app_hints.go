@@ -0,0 +1,135 @@
+package client
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/gianluca-marchini/devicedetector/parser"
+)
+
+const FixtureFileAppHints = `hints/apps.yml`
+const FixtureFileBrowserHints = `hints/browsers.yml`
+
+// Header in which the Android webviews send the package name of their app
+const HeaderRequestedWith = `X-Requested-With`
+
+type AppHint struct {
+	Name string `yaml:"name" json:"name"`
+	// Short code of the app, never one of the browsers' codes
+	ShortName string `yaml:"short_name" json:"short_name"`
+}
+
+// Maps the package names sent by the Android webviews in X-Requested-With to
+// the apps, or to the browsers for the packages of browsers
+type AppHints struct {
+	apps         map[string]*AppHint
+	browsers     map[string]string
+	appsFile     string
+	browsersFile string
+}
+
+// Load the package mappings: appsFile maps the packages to the name and
+// short name of the apps, browsersFile to the name of known browsers
+func NewAppHints(fsys fs.FS, appsFile, browsersFile string) (*AppHints, error) {
+	h := &AppHints{
+		apps:         make(map[string]*AppHint),
+		browsers:     make(map[string]string),
+		appsFile:     appsFile,
+		browsersFile: browsersFile,
+	}
+	if err := readAppHints(fsys, appsFile, h.apps); err != nil {
+		return nil, err
+	}
+	if err := readBrowserHints(fsys, browsersFile, h.browsers); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func readAppHints(fsys fs.FS, file string, into map[string]*AppHint) error {
+	var apps map[string]*AppHint
+	if err := parser.ReadYamlFS(fsys, file, &apps); err != nil {
+		return err
+	}
+	for pkg, app := range apps {
+		if app == nil || app.Name == "" {
+			return fmt.Errorf("%s: empty app name for %q", file, pkg)
+		}
+		if app.ShortName == "" {
+			return fmt.Errorf("%s: empty short name for %q", file, pkg)
+		}
+		// the browser families are looked up by short name
+		if _, ok := availableBrowsers[app.ShortName]; ok {
+			return fmt.Errorf("%s: short name %q of %q is a browser's", file, app.ShortName, pkg)
+		}
+		into[strings.ToLower(pkg)] = app
+	}
+	return nil
+}
+
+func readBrowserHints(fsys fs.FS, file string, into map[string]string) error {
+	var browsers map[string]string
+	if err := parser.ReadYamlFS(fsys, file, &browsers); err != nil {
+		return err
+	}
+	for pkg, name := range browsers {
+		if _, ok := GetBrowserShortName(name); !ok {
+			return fmt.Errorf("%s: unknown browser %q for %q", file, name, pkg)
+		}
+		into[strings.ToLower(pkg)] = name
+	}
+	return nil
+}
+
+// Merges the hints/apps.yml and hints/browsers.yml overlay files found in
+// dir over the loaded mappings
+func (h *AppHints) ApplyOverlay(dir string) error {
+	apps := make(map[string]*AppHint)
+	if file, ok := parser.OverlayFile(filepath.Join(dir, "hints"), h.appsFile); ok {
+		if err := readAppHints(nil, file, apps); err != nil {
+			return err
+		}
+	}
+	browsers := make(map[string]string)
+	if file, ok := parser.OverlayFile(filepath.Join(dir, "hints"), h.browsersFile); ok {
+		if err := readBrowserHints(nil, file, browsers); err != nil {
+			return err
+		}
+	}
+	for pkg, app := range apps {
+		h.apps[pkg] = app
+	}
+	for pkg, name := range browsers {
+		h.browsers[pkg] = name
+	}
+	return nil
+}
+
+// Returns the client identified by the package name sent in
+// X-Requested-With: the browser for the packages of browsers, the mobile app
+// otherwise. nil for unknown packages and for XMLHttpRequest, which scripts
+// send in the same header.
+func (h *AppHints) Parse(pkg string) *ClientMatchResult {
+	pkg = strings.ToLower(strings.TrimSpace(pkg))
+	if pkg == "" || pkg == "xmlhttprequest" {
+		return nil
+	}
+	if name, ok := h.browsers[pkg]; ok {
+		short, _ := GetBrowserShortName(name)
+		return &ClientMatchResult{
+			Type:      ParserNameBrowser,
+			Name:      availableBrowsers[short],
+			ShortName: short,
+		}
+	}
+	if app, ok := h.apps[pkg]; ok {
+		return &ClientMatchResult{
+			Type:      ParserNameMobileApp,
+			Name:      app.Name,
+			ShortName: app.ShortName,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppHintsParse(t *testing.T) {
+	h, err := NewAppHints(nil, filepath.Join(dir, FixtureFileAppHints), filepath.Join(dir, FixtureFileBrowserHints))
+	require.NoError(t, err)
+
+	require.Equal(t, &ClientMatchResult{
+		Type:      ParserNameMobileApp,
+		Name:      "Instagram App",
+		ShortName: "IGA",
+	}, h.Parse("com.instagram.android"))
+	require.Equal(t, &ClientMatchResult{
+		Type:      ParserNameBrowser,
+		Name:      "Samsung Browser",
+		ShortName: "SB",
+	}, h.Parse("com.sec.android.app.sbrowser"))
+	// package names are matched case-insensitively
+	require.Equal(t, "UC Browser", h.Parse("com.ucmobile.intl").Name)
+
+	require.Nil(t, h.Parse("XMLHttpRequest"))
+	require.Nil(t, h.Parse("com.example.unknown"))
+	require.Nil(t, h.Parse(""))
+}
+
+func TestAppHintsShortNames(t *testing.T) {
+	h, err := NewAppHints(nil, filepath.Join(dir, FixtureFileAppHints), filepath.Join(dir, FixtureFileBrowserHints))
+	require.NoError(t, err)
+
+	// the short names of the browsers are kept for the browsers
+	overlay := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(overlay, "hints"), 0o755))
+	file := filepath.Join(overlay, "hints", filepath.Base(FixtureFileAppHints))
+	require.NoError(t, os.WriteFile(file, []byte("'com.example.app':\n  name: 'Example'\n  short_name: 'CH'\n"), 0o644))
+	require.Error(t, h.ApplyOverlay(overlay))
+
+	require.NoError(t, os.WriteFile(file, []byte("'com.example.app':\n  name: 'Example'\n  short_name: 'EXA'\n"), 0o644))
+	require.NoError(t, h.ApplyOverlay(overlay))
+	require.Equal(t, "EXA", h.Parse("com.example.app").ShortName)
+}
@@ -1,7 +1,11 @@
 package client
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"path/filepath"
+	"strings"
 
 	gover "github.com/mcuadros/go-version"
 
@@ -309,6 +313,16 @@ func GetBrowserFamily(browserLabel string) (string, bool) {
 	return "", false
 }
 
+// Returns the short code of the given browser name
+func GetBrowserShortName(name string) (string, bool) {
+	for browserShort, browserName := range availableBrowsers {
+		if parser.StringEqualIgnoreCase(name, browserName) {
+			return browserShort, true
+		}
+	}
+	return "", false
+}
+
 // Returns if the given browser is mobile only
 func IsMobileOnlyBrowser(browser string) bool {
 	if parser.ArrayContainsString(mobileOnlyBrowsers, browser) {
@@ -327,52 +341,141 @@ type Engine struct {
 	Versions map[string]string `yaml:"versions" json:"versions"`
 }
 
+// Modes of the browsers loading the pages through the servers of their vendor
+const (
+	// The pages, scripts included, are executed on the vendor's servers and
+	// the device only displays the result
+	ProxyModeServerRendered = "server rendered"
+	// The vendor's servers fetch the pages and rewrite them into lighter
+	// pages, stripping most scripts
+	ProxyModeTranscoded = "transcoded"
+)
+
+// Proxy of a browser rule. When regex is set, the rule only proxies the user
+// agents matching it, e.g. Silk-Accelerated=true.
+type Proxy struct {
+	parser.Regular `yaml:",inline" json:",inline"`
+	Vendor         string `yaml:"vendor" json:"vendor"`
+	Mode           string `yaml:"mode" json:"mode"`
+}
+
+func (p *Proxy) match(ua string) bool {
+	return p.Regex == "" || p.IsMatchUserAgent(ua)
+}
+
 type BrowserItem struct {
 	parser.Regular `yaml:",inline" json:",inline"`
 	Name           string  `yaml:"name" json:"name"`
 	Version        string  `yaml:"version" json:"version"`
 	Engine         *Engine `yaml:"engine" json:"engine"`
+	Proxy          *Proxy  `yaml:"proxy" json:"proxy"`
+	Disabled       bool    `yaml:"disabled" json:"disabled"`
+}
+
+// Checks a user-defined rule: the browser must be known, unless named after
+// the groups of the regex, and the regexes must compile
+func (item *BrowserItem) check() error {
+	if !strings.Contains(item.Name, "$") {
+		if _, ok := GetBrowserShortName(item.Name); !ok {
+			return fmt.Errorf("unknown browser %q", item.Name)
+		}
+	}
+	if err := item.Validate(); err != nil {
+		return err
+	}
+	if item.Proxy != nil && item.Proxy.Regex != "" {
+		if err := item.Proxy.Validate(); err != nil {
+			return fmt.Errorf("proxy of %s: %w", item.Name, err)
+		}
+	}
+	return nil
 }
 
 // Client parser for browser detection
 type Browser struct {
 	Regexes  []*BrowserItem
+	file     string
 	engine   BrowserEngine
 	verCache map[string]*Version
+	// Safari versions of the WebKit builds, for the Safari useragents without
+	// Version/. nil without webkit_versions.yml, which is optional
+	webKitVersions *parser.BuildVersions
+	msieReg        parser.Regular
 }
 
 const ParserNameBrowser = `browser`
 const FixtureFileBrowser = `browsers.yml`
+const FixtureFileWebKitVersion = `webkit_versions.yml`
 
 func init() {
 	RegClientParser(ParserNameBrowser,
-		func(dir string) ClientParser {
-			return NewBrowser(filepath.Join(dir, FixtureFileBrowser))
+		func(fsys fs.FS, dir string) ClientParser {
+			return NewBrowser(fsys, filepath.Join(dir, FixtureFileBrowser))
 		})
 }
 
-func NewBrowser(fileName string) *Browser {
+func NewBrowser(fsys fs.FS, fileName string) *Browser {
 	b := &Browser{}
 	b.engine.ParserName = ParserNameBrowserEngine
-	if err := b.Load(fileName); err != nil {
+	if err := b.Load(fsys, fileName); err != nil {
 		return nil
 	}
 	return b
 }
 
-func (b *Browser) Load(file string) error {
+func (b *Browser) Load(fsys fs.FS, file string) error {
 	b.verCache = make(map[string]*Version)
+	b.msieReg = parser.Regular{Regex: `MSIE (\d+[\.\d]*)`}
+	b.msieReg.Compile()
 	var v []*BrowserItem
-	err := parser.ReadYamlFile(file, &v)
+	err := parser.ReadYamlFS(fsys, file, &v)
 	if err != nil {
 		return err
 	}
 	engineFile := file[0:len(file)-len(FixtureFileBrowser)] + FixtureFileBrowserEngine
-	err = b.engine.Load(engineFile)
+	err = b.engine.Load(fsys, engineFile)
 	if err != nil {
 		return err
 	}
+	webKitFile := file[0:len(file)-len(FixtureFileBrowser)] + FixtureFileWebKitVersion
+	b.webKitVersions, err = parser.NewBuildVersions(fsys, webKitFile, `AppleWebKit/(\d+(?:\.\d+)*)`)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
 	b.Regexes = v
+	b.file = file
+	return nil
+}
+
+// Merges the browsers and browser engines overlay files found in dir
+func (b *Browser) ApplyOverlay(dir string) error {
+	if err := b.engine.ApplyOverlay(dir); err != nil {
+		return err
+	}
+	regexes, err := parser.MergeOverlay(dir, b.file, b.Regexes,
+		func(item *BrowserItem) (string, bool) { return item.Name, item.Disabled },
+		(*BrowserItem).check)
+	if err != nil {
+		return err
+	}
+	b.Regexes = regexes
+	return nil
+}
+
+// Adds a rule ahead of the loaded ones. The name must be one of the known
+// browsers, unless it references the regex groups ($1, $2...).
+// engine is optional: when nil the engine is detected from the useragent.
+func (b *Browser) AddRule(regex, name, version string, engine *Engine) error {
+	item := &BrowserItem{
+		Regular: parser.Regular{Regex: regex},
+		Name:    name,
+		Version: version,
+		Engine:  engine,
+	}
+	if err := item.check(); err != nil {
+		return err
+	}
+	b.Regexes = append([]*BrowserItem{item}, b.Regexes...)
 	return nil
 }
 
@@ -388,9 +491,12 @@ func (b *Browser) Parse(ua string) *BrowserMatchResult {
 			for browserShort, browserName := range availableBrowsers {
 				if parser.StringEqualIgnoreCase(name, browserName) {
 					version := parser.BuildVersion(regex.Version, matches)
+					if version == "" && (browserShort == `SF` || browserShort == `MF`) && b.webKitVersions != nil {
+						version = b.webKitVersions.Parse(ua)
+					}
 					engine := b.BuildEngine(regex.Engine, version, ua)
 					engineVersion := b.BuildEngineVersion(engine, ua)
-					return &BrowserMatchResult{
+					result := &BrowserMatchResult{
 						Type:          ParserNameBrowser,
 						Name:          browserName,
 						ShortName:     browserShort,
@@ -398,6 +504,12 @@ func (b *Browser) Parse(ua string) *BrowserMatchResult {
 						Engine:        engine,
 						EngineVersion: engineVersion,
 					}
+					if regex.Proxy != nil && regex.Proxy.match(ua) {
+						result.ProxyVendor = regex.Proxy.Vendor
+						result.ProxyMode = regex.Proxy.Mode
+					}
+					b.fixCompatibilityView(result, ua)
+					return result
 				}
 			}
 		}
@@ -405,12 +517,46 @@ func (b *Browser) Parse(ua string) *BrowserMatchResult {
 	return nil
 }
 
+// Versions of Internet Explorer shipped with the Trident major versions
+var tridentVersions = map[string]string{
+	`4`: `8.0`,
+	`5`: `9.0`,
+	`6`: `10.0`,
+	`7`: `11.0`,
+	`8`: `11.0`,
+}
+
+// Internet Explorer in compatibility view reports the MSIE version of the
+// release it emulates (MSIE 7.0), while the Trident version tells the real
+// one. The version of r is corrected and CompatibilityMode set when the MSIE
+// version is the older.
+func (b *Browser) fixCompatibilityView(r *BrowserMatchResult, ua string) {
+	if r.ShortName != `IE` || r.Engine != `Trident` {
+		return
+	}
+	major, _, _ := strings.Cut(r.EngineVersion, ".")
+	version, ok := tridentVersions[major]
+	if !ok {
+		return
+	}
+	m := b.msieReg.MatchUserAgent(ua)
+	if len(m) < 2 || gover.CompareSimple(m[1], version) >= 0 {
+		return
+	}
+	r.Version = parser.BuildVersion(version, nil)
+	r.CompatibilityMode = true
+}
+
 func (b *Browser) BuildEngine(engineData *Engine, browserVersion, ua string) string {
 	engine := ""
 	if engineData != nil {
 		engine = engineData.Default
+		// the engine of the highest version not above the browser one wins
+		matched := ""
 		for version, versionEngine := range engineData.Versions {
-			if gover.CompareSimple(browserVersion, version) >= 0 {
+			if gover.CompareSimple(browserVersion, version) >= 0 &&
+				(matched == "" || gover.CompareSimple(version, matched) > 0) {
+				matched = version
 				engine = versionEngine
 			}
 		}
@@ -424,14 +570,20 @@ func (b *Browser) BuildEngine(engineData *Engine, browserVersion, ua string) str
 }
 
 func (b *Browser) BuildEngineVersion(engine, ua string) string {
+	return buildEngineVersion(b.verCache, engine, ua)
+}
+
+// Parses the version of engine in ua, with the version regexes cached in
+// verCache
+func buildEngineVersion(verCache map[string]*Version, engine, ua string) string {
 	if engine == "" {
 		return ""
 	}
-	v, ok := b.verCache[engine]
+	v, ok := verCache[engine]
 	if !ok {
 		v = &Version{Engine: engine}
 		v.Compile()
-		b.verCache[engine] = v
+		verCache[engine] = v
 	}
 	return v.Parse(ua)
 }
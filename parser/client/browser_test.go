@@ -1,6 +1,7 @@
 package client
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -9,7 +10,7 @@ import (
 )
 
 func TestBrowserParse(t *testing.T) {
-	ps := NewBrowser(filepath.Join(dir, FixtureFileBrowser))
+	ps := NewBrowser(nil, filepath.Join(dir, FixtureFileBrowser))
 	var list []*ClientFixture
 	err := parser.ReadYamlFile(`fixtures/browser.yml`, &list)
 	if err != nil {
@@ -22,3 +23,20 @@ func TestBrowserParse(t *testing.T) {
 		require.EqualValues(t, item.ClientMatchResult, r)
 	}
 }
+
+func TestBrowserOverlay(t *testing.T) {
+	ps := NewBrowser(nil, filepath.Join(dir, FixtureFileBrowser))
+	overlay := t.TempDir()
+	file := filepath.Join(overlay, FixtureFileBrowser)
+
+	// the overlays are checked like the rules added with AddRule
+	require.NoError(t, os.WriteFile(file, []byte("- regex: 'Acme/(\\d+)'\n  name: 'Not A Browser'\n"), 0o644))
+	require.Error(t, ps.ApplyOverlay(overlay))
+	require.Error(t, ps.AddRule(`Acme/(\d+)`, `Not A Browser`, `$1`, nil))
+
+	require.NoError(t, os.WriteFile(file, []byte("- regex: 'Acme/(\\d+)'\n  name: 'Kiwi'\n  version: '$1'\n"), 0o644))
+	require.NoError(t, ps.ApplyOverlay(overlay))
+	r := ps.Parse(`Acme/42`)
+	require.Equal(t, `Kiwi`, r.Name)
+	require.Equal(t, `42`, r.Version)
+}
@@ -1,6 +1,8 @@
 package client
 
 import (
+	"errors"
+	"io/fs"
 	"sort"
 	"strings"
 
@@ -15,6 +17,14 @@ type ClientMatchResult struct {
 	ShortName     string `yaml:"short_name" json:"short_name"`
 	Engine        string `yaml:"engine" json:"engine"`
 	EngineVersion string `yaml:"engine_version" json:"engine_version"`
+
+	// Set for the browsers loading the pages through their vendor's servers
+	ProxyVendor string `yaml:"proxy_vendor,omitempty" json:"proxy_vendor,omitempty"`
+	ProxyMode   string `yaml:"proxy_mode,omitempty" json:"proxy_mode,omitempty"`
+
+	// Set for Internet Explorer in compatibility view, reporting the MSIE
+	// version of an older release: Version is the real one
+	CompatibilityMode bool `yaml:"compatibility_mode,omitempty" json:"compatibility_mode,omitempty"`
 }
 
 type ClientParser interface {
@@ -26,18 +36,20 @@ type ClientReg struct {
 	parser.Regular `yaml:",inline" json:",inline"`
 	Name           string `yaml:"name" json:"name"`
 	Version        string `yaml:"version" json:"version"`
+	Disabled       bool   `yaml:"disabled" json:"disabled"`
 }
 
 // Parses the current UA and checks whether it contains any client information
 type ClientParserAbstract struct {
 	Regexes      []*ClientReg
 	ParserName   string
+	file         string
 	overAllMatch parser.Regular
 }
 
-func (c *ClientParserAbstract) Load(file string) error {
+func (c *ClientParserAbstract) Load(fsys fs.FS, file string) error {
 	var v []*ClientReg
-	err := parser.ReadYamlFile(file, &v)
+	err := parser.ReadYamlFS(fsys, file, &v)
 	if err != nil {
 		return err
 	}
@@ -45,6 +57,38 @@ func (c *ClientParserAbstract) Load(file string) error {
 		item.Compile()
 	}
 	c.Regexes = v
+	c.file = file
+	return nil
+}
+
+func (c *ClientParserAbstract) ApplyOverlay(dir string) error {
+	regexes, err := parser.MergeOverlay(dir, c.file, c.Regexes,
+		func(item *ClientReg) (string, bool) { return item.Name, item.Disabled },
+		(*ClientReg).Validate)
+	if err != nil {
+		return err
+	}
+	c.Regexes = regexes
+	c.overAllMatch = parser.Regular{}
+	return nil
+}
+
+// Adds a rule ahead of the loaded ones.
+// name and version may reference the regex groups ($1, $2...).
+func (c *ClientParserAbstract) AddRule(regex, name, version string) error {
+	if name == "" {
+		return errors.New("empty client name")
+	}
+	item := &ClientReg{
+		Regular: parser.Regular{Regex: regex},
+		Name:    name,
+		Version: version,
+	}
+	if err := item.Validate(); err != nil {
+		return err
+	}
+	c.Regexes = append([]*ClientReg{item}, c.Regexes...)
+	c.overAllMatch = parser.Regular{}
 	return nil
 }
 
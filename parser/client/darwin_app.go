@@ -0,0 +1,49 @@
+package client
+
+import (
+	"io/fs"
+
+	"github.com/gianluca-marchini/devicedetector/parser"
+)
+
+const ParserNameDarwinApp = `darwin app`
+
+func init() {
+	RegClientParser(ParserNameDarwinApp,
+		func(fsys fs.FS, dir string) ClientParser {
+			return NewDarwinApp()
+		})
+}
+
+// Client parser reporting the native iOS and macOS apps as a mobile app
+// named after the leading product token of their useragent:
+// MyApp/3.2 CFNetwork/1408.0.4 Darwin/22.5.0. Meant to run after the other
+// client parsers, which know the media players and apps sending the same
+// CFNetwork useragents.
+type DarwinApp struct {
+	reg parser.Regular
+}
+
+func NewDarwinApp() *DarwinApp {
+	p := &DarwinApp{
+		reg: parser.Regular{Regex: `^(?!Mozilla/|Safari/|MobileSafari/|com\.apple\.)([^/]+)/(\d+(?:\.\d+)*)(?: \([^)]*\))? CFNetwork/`},
+	}
+	p.reg.Compile()
+	return p
+}
+
+func (p *DarwinApp) PreMatch(ua string) bool {
+	return p.reg.IsMatchUserAgent(ua)
+}
+
+func (p *DarwinApp) Parse(ua string) *ClientMatchResult {
+	m := p.reg.MatchUserAgent(ua)
+	if len(m) != 3 {
+		return nil
+	}
+	return &ClientMatchResult{
+		Type:    ParserNameMobileApp,
+		Name:    m[1],
+		Version: parser.BuildVersion(m[2], nil),
+	}
+}
@@ -0,0 +1,24 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDarwinAppParse(t *testing.T) {
+	p := NewDarwinApp()
+	require.Equal(t, &ClientMatchResult{
+		Type:    ParserNameMobileApp,
+		Name:    "MyApp",
+		Version: "3.2",
+	}, p.Parse(`MyApp/3.2 (iPhone) CFNetwork/1408.0.4 Darwin/22.5.0`))
+
+	for _, ua := range []string{
+		`MobileSafari/604.1 CFNetwork/1408.0.4 Darwin/22.5.0`,
+		`com.apple.WebKit.Networking/8614.2.9.0.10 CFNetwork/1399 Darwin/22.1.0`,
+		`Mozilla/5.0 (iPhone; CPU iPhone OS 16_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.1 Mobile/15E148 Safari/604.1`,
+	} {
+		require.Nil(t, p.Parse(ua), ua)
+	}
+}
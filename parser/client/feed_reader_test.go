@@ -9,7 +9,7 @@ import (
 )
 
 func TestFeedReaderParse(t *testing.T) {
-	ps := NewFeedReader(filepath.Join(dir, FixtureFileFeedReader))
+	ps := NewFeedReader(nil, filepath.Join(dir, FixtureFileFeedReader))
 	var list []*ClientFixture
 	err := parser.ReadYamlFile(`fixtures/feed_reader.yml`, &list)
 	if err != nil {
@@ -0,0 +1,80 @@
+package client
+
+import "io/fs"
+
+const ParserNameInAppBrowser = `in-app browser`
+const FixtureFileInAppBrowser = `in_app_browsers.yml`
+
+type InAppBrowserMatchResult struct {
+	// App embedding the webview
+	App        string `yaml:"app" json:"app"`
+	AppVersion string `yaml:"app_version" json:"app_version"`
+	// Browser the webview is reported as, if any
+	Browser        string `yaml:"browser" json:"browser"`
+	BrowserVersion string `yaml:"browser_version" json:"browser_version"`
+	Engine         string `yaml:"engine" json:"engine"`
+	EngineVersion  string `yaml:"engine_version" json:"engine_version"`
+}
+
+// Detects the webviews of the apps opening links inside themselves
+// (Facebook, Instagram, WeChat...), whichever client the useragent is
+// classified as
+type InAppBrowser struct {
+	ClientParserAbstract
+	engine   BrowserEngine
+	verCache map[string]*Version
+}
+
+// Loads fileName and the browser engines of browser_engine.yml in the same
+// directory
+func NewInAppBrowser(fsys fs.FS, fileName string) (*InAppBrowser, error) {
+	c := &InAppBrowser{verCache: make(map[string]*Version)}
+	c.ParserName = ParserNameInAppBrowser
+	c.engine.ParserName = ParserNameBrowserEngine
+	if err := c.Load(fsys, fileName); err != nil {
+		return nil, err
+	}
+	engineFile := fileName[0:len(fileName)-len(FixtureFileInAppBrowser)] + FixtureFileBrowserEngine
+	if err := c.engine.Load(fsys, engineFile); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Merges the in-app browsers and browser engines overlay files found in dir
+func (p *InAppBrowser) ApplyOverlay(dir string) error {
+	if err := p.engine.ApplyOverlay(dir); err != nil {
+		return err
+	}
+	return p.ClientParserAbstract.ApplyOverlay(dir)
+}
+
+// Parses ua for the embedding app and the engine of its webview. The
+// useragents without a browser engine token are those of the app itself
+// (API calls...) and not of its webview: nil is returned for them. The
+// browser of the webview is taken from c, the client already detected in
+// ua, when it's a browser.
+func (p *InAppBrowser) Parse(ua string, c *ClientMatchResult) *InAppBrowserMatchResult {
+	app := p.ClientParserAbstract.Parse(ua)
+	if app == nil {
+		return nil
+	}
+	engine := p.engine.Parse(ua)
+	if engine == nil {
+		return nil
+	}
+	r := &InAppBrowserMatchResult{
+		App:        app.Name,
+		AppVersion: app.Version,
+	}
+	if c != nil && c.Type == ParserNameBrowser && c.Engine != "" {
+		r.Browser = c.Name
+		r.BrowserVersion = c.Version
+		r.Engine = c.Engine
+		r.EngineVersion = c.EngineVersion
+	} else {
+		r.Engine = engine.Name
+		r.EngineVersion = buildEngineVersion(p.verCache, r.Engine, ua)
+	}
+	return r
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gianluca-marchini/devicedetector/parser"
+	"github.com/stretchr/testify/require"
+)
+
+type inAppBrowserFixture struct {
+	UserAgent    string                   `yaml:"user_agent"`
+	InAppBrowser *InAppBrowserMatchResult `yaml:"in_app_browser"`
+}
+
+func TestInAppBrowserParse(t *testing.T) {
+	ps, err := NewInAppBrowser(nil, filepath.Join(dir, FixtureFileInAppBrowser))
+	require.NoError(t, err)
+	browser := NewBrowser(nil, filepath.Join(dir, FixtureFileBrowser))
+	var list []*inAppBrowserFixture
+	err = parser.ReadYamlFile(`fixtures/in_app_browser.yml`, &list)
+	if err != nil {
+		t.Error(err)
+	}
+
+	for _, item := range list {
+		r := ps.Parse(item.UserAgent, browser.Parse(item.UserAgent))
+		require.EqualValues(t, item.InAppBrowser, r, item.UserAgent)
+	}
+
+	// with the app as client, the engine is parsed from the ua
+	ua := list[0].UserAgent
+	app := &ClientMatchResult{Type: ParserNameMobileApp, Name: "Facebook"}
+	require.Equal(t, &InAppBrowserMatchResult{App: "Facebook", AppVersion: "397.0.0.23.404", Engine: "WebKit", EngineVersion: "537.36"}, ps.Parse(ua, app))
+	require.Equal(t, ps.Parse(ua, app), ps.Parse(ua, nil))
+	// the app useragents without an engine token are rejected whatever c
+	ua = `Instagram 219.0.0.12.117 Android (30/11; 420dpi; 1080x2201; samsung; SM-A515F; a51; exynos9611; en_US; 346138365)`
+	require.Nil(t, ps.Parse(ua, &ClientMatchResult{Type: ParserNameBrowser, Name: "Android Browser", Engine: "WebKit"}))
+	require.Nil(t, ps.Parse(ua, nil))
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"io/fs"
 	"path/filepath"
 )
 
@@ -9,15 +10,15 @@ const FixtureFileLibrary = `libraries.yml`
 
 func init() {
 	RegClientParser(ParserNameLibrary,
-		func(dir string) ClientParser {
-			return NewLibrary(filepath.Join(dir, FixtureFileLibrary))
+		func(fsys fs.FS, dir string) ClientParser {
+			return NewLibrary(fsys, filepath.Join(dir, FixtureFileLibrary))
 		})
 }
 
-func NewLibrary(fileName string) *Library {
+func NewLibrary(fsys fs.FS, fileName string) *Library {
 	c := &Library{}
 	c.ParserName = ParserNameLibrary
-	if err := c.Load(fileName); err != nil {
+	if err := c.Load(fsys, fileName); err != nil {
 		return nil
 	}
 	return c
@@ -9,7 +9,7 @@ import (
 )
 
 func TestLibraryParse(t *testing.T) {
-	var ps = NewLibrary(filepath.Join(dir, FixtureFileLibrary))
+	var ps = NewLibrary(nil, filepath.Join(dir, FixtureFileLibrary))
 	var list []*ClientFixture
 	err := parser.ReadYamlFile(`fixtures/library.yml`, &list)
 	if err != nil {
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"io/fs"
 	"path/filepath"
 )
 
@@ -9,15 +10,15 @@ const FixtureFileMediaPlayer = `mediaplayers.yml`
 
 func init() {
 	RegClientParser(ParserNameMediaPlayer,
-		func(dir string) ClientParser {
-			return NewMediaPlayer(filepath.Join(dir, FixtureFileMediaPlayer))
+		func(fsys fs.FS, dir string) ClientParser {
+			return NewMediaPlayer(fsys, filepath.Join(dir, FixtureFileMediaPlayer))
 		})
 }
 
-func NewMediaPlayer(fileName string) *MediaPlayer {
+func NewMediaPlayer(fsys fs.FS, fileName string) *MediaPlayer {
 	c := &MediaPlayer{}
 	c.ParserName = ParserNameMediaPlayer
-	if err := c.Load(fileName); err != nil {
+	if err := c.Load(fsys, fileName); err != nil {
 		return nil
 	}
 	return c
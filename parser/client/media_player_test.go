@@ -9,7 +9,7 @@ import (
 )
 
 func TestMediaPlayerParse(t *testing.T) {
-	ps := NewMediaPlayer(filepath.Join(dir, FixtureFileMediaPlayer))
+	ps := NewMediaPlayer(nil, filepath.Join(dir, FixtureFileMediaPlayer))
 	var list []*ClientFixture
 	err := parser.ReadYamlFile(`fixtures/mediaplayer.yml`, &list)
 	if err != nil {
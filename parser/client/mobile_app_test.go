@@ -9,7 +9,7 @@ import (
 )
 
 func TestMediaAppParse(t *testing.T) {
-	ps := NewMobileApp(filepath.Join(dir, FixtureFileMobileApp))
+	ps := NewMobileApp(nil, filepath.Join(dir, FixtureFileMobileApp))
 	var list []*ClientFixture
 	err := parser.ReadYamlFile(`fixtures/mobile_app.yml`, &list)
 	if err != nil {
@@ -9,7 +9,7 @@ import (
 )
 
 func TestPimParse(t *testing.T) {
-	ps := NewPim(filepath.Join(dir, FixtureFilePim))
+	ps := NewPim(nil, filepath.Join(dir, FixtureFilePim))
 	var list []*ClientFixture
 	err := parser.ReadYamlFile(`fixtures/pim.yml`, &list)
 	if err != nil {
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/gianluca-marchini/devicedetector/parser"
+)
+
+const ParserNameWebView = `webview`
+const FixtureFileWebView = `webviews.yml`
+
+// Kinds of webview
+const (
+	WebViewNone    = ""
+	WebViewAndroid = "Android WebView"
+	WebViewIos     = "WKWebView"
+)
+
+// A rule with an empty kind marks the useragents it matches as not being
+// webviews, ahead of the rules below it
+type WebViewReg struct {
+	parser.Regular `yaml:",inline" json:",inline"`
+	Kind           string `yaml:"kind" json:"kind"`
+}
+
+// Tells the webviews embedded in apps apart from the browsers they are
+// reported as (Chrome Webview, Mobile Safari...)
+type WebView struct {
+	Regexes      []*WebViewReg
+	file         string
+	overAllMatch parser.Regular
+}
+
+func NewWebView(fsys fs.FS, file string) (*WebView, error) {
+	var v []*WebViewReg
+	if err := parser.ReadYamlFS(fsys, file, &v); err != nil {
+		return nil, err
+	}
+	for _, item := range v {
+		item.Compile()
+	}
+	return &WebView{
+		Regexes: v,
+		file:    file,
+	}, nil
+}
+
+// Adds the rules of the overlay file found in dir ahead of the loaded ones
+func (w *WebView) ApplyOverlay(dir string) error {
+	file, ok := parser.OverlayFile(dir, w.file)
+	if !ok {
+		return nil
+	}
+	var v []*WebViewReg
+	if err := parser.ReadYamlFile(file, &v); err != nil {
+		return err
+	}
+	for _, item := range v {
+		if err := item.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	w.Regexes = append(v, w.Regexes...)
+	w.overAllMatch = parser.Regular{}
+	return nil
+}
+
+// Matches ua against all the rules at once, without telling the kind
+func (w *WebView) PreMatch(ua string) bool {
+	if w.overAllMatch.Regexp == nil {
+		count := len(w.Regexes)
+		if count == 0 {
+			return false
+		}
+		sb := strings.Builder{}
+		sb.WriteString(w.Regexes[count-1].Regex)
+		for i := count - 2; i >= 0; i-- {
+			sb.WriteString("|")
+			sb.WriteString(w.Regexes[i].Regex)
+		}
+		w.overAllMatch.Regex = sb.String()
+		w.overAllMatch.Compile()
+	}
+	return w.overAllMatch.IsMatchUserAgent(ua)
+}
+
+// Returns the kind of webview ua comes from, WebViewNone for the others
+func (w *WebView) Parse(ua string) string {
+	if !w.PreMatch(ua) {
+		return WebViewNone
+	}
+	for _, regex := range w.Regexes {
+		if regex.IsMatchUserAgent(ua) {
+			return regex.Kind
+		}
+	}
+	return WebViewNone
+}
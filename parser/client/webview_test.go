@@ -0,0 +1,29 @@
+package client
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gianluca-marchini/devicedetector/parser"
+	"github.com/stretchr/testify/require"
+)
+
+type webViewFixture struct {
+	UserAgent string `yaml:"user_agent"`
+	Kind      string `yaml:"kind"`
+}
+
+func TestWebViewParse(t *testing.T) {
+	ps, err := NewWebView(nil, filepath.Join(dir, FixtureFileWebView))
+	require.NoError(t, err)
+	var list []*webViewFixture
+	err = parser.ReadYamlFile(`fixtures/webview.yml`, &list)
+	if err != nil {
+		t.Error(err)
+	}
+
+	for _, item := range list {
+		require.Equal(t, item.Kind, ps.Parse(item.UserAgent), item.UserAgent)
+	}
+	require.False(t, ps.PreMatch(`curl/7.88.1`))
+}
@@ -0,0 +1,140 @@
+package parser
+
+import (
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+const ParserNameDarwin = "darwin"
+const FixtureFileDarwin = "darwin.yml"
+
+type DarwinVersion struct {
+	// Darwin version, major.minor or major alone
+	Version string `yaml:"version" json:"version"`
+	Ios     string `yaml:"ios" json:"ios"`
+	Mac     string `yaml:"mac" json:"mac"`
+}
+
+// Range of CFNetwork builds shipped with an iOS and a macOS release
+type CFNetworkVersion struct {
+	From int    `yaml:"from" json:"from"`
+	To   int    `yaml:"to" json:"to"`
+	Ios  string `yaml:"ios" json:"ios"`
+	Mac  string `yaml:"mac" json:"mac"`
+}
+
+type darwinFile struct {
+	Darwin    []*DarwinVersion    `yaml:"darwin"`
+	CFNetwork []*CFNetworkVersion `yaml:"cfnetwork"`
+}
+
+// Os parser for the native iOS and macOS apps, mapping the Darwin and
+// CFNetwork versions of their useragent to the os version. The device type
+// isn't inferred here: the Darwin and CFNetwork versions are the same on an
+// iPhone and an iPad, and the iPhone, iPad or x86_64 tokens telling the
+// device are read by the device parsers and the desktop os heuristic.
+type DarwinOs struct {
+	darwin    map[string]*DarwinVersion
+	cfNetwork []*CFNetworkVersion
+
+	darwinReg    Regular
+	cfNetworkReg Regular
+	// hints of the apps running on a Mac or an Apple TV rather than on iOS
+	macReg Regular
+	tvReg  Regular
+}
+
+func NewDarwinOs(fsys fs.FS, file string) (*DarwinOs, error) {
+	var v darwinFile
+	if err := ReadYamlFS(fsys, file, &v); err != nil {
+		return nil, err
+	}
+	d := &DarwinOs{
+		darwin:       make(map[string]*DarwinVersion, len(v.Darwin)),
+		cfNetwork:    v.CFNetwork,
+		darwinReg:    Regular{Regex: `CFNetwork/\d+.*Darwin/(\d+)\.(\d+)`},
+		cfNetworkReg: Regular{Regex: `CFNetwork/(\d+)`},
+		macReg:       Regular{Regex: `\((?:x86_64|arm64)\)|Macintosh|MacBook|iMac|Mac(?:mini|Pro)?\d+,\d+|^Safari/`},
+		tvReg:        Regular{Regex: `AppleTV|Apple TV`},
+	}
+	for _, r := range []*Regular{&d.darwinReg, &d.cfNetworkReg, &d.macReg, &d.tvReg} {
+		r.Compile()
+	}
+	for _, item := range v.Darwin {
+		if item.Version == "" {
+			return nil, fmt.Errorf("%s: empty darwin version", file)
+		}
+		d.darwin[item.Version] = item
+	}
+	return d, nil
+}
+
+func (d *DarwinOs) PreMatch(ua string) bool {
+	return d.cfNetworkReg.IsMatchUserAgent(ua)
+}
+
+// Returns the os of a native app useragent whose Darwin or CFNetwork version
+// is in the tables, nil otherwise
+func (d *DarwinOs) Parse(ua string) *OsMatchResult {
+	if !d.PreMatch(ua) {
+		return nil
+	}
+	mac := d.macReg.IsMatchUserAgent(ua)
+	version := ""
+	if m := d.darwinReg.MatchUserAgent(ua); len(m) == 3 {
+		version = d.darwinVersion(m[1], m[2], mac)
+	} else if m := d.cfNetworkReg.MatchUserAgent(ua); len(m) == 2 {
+		version = d.cfNetworkVersion(m[1], mac)
+	}
+	if version == "" {
+		return nil
+	}
+	r := &OsMatchResult{Version: BuildVersion(version, nil)}
+	switch {
+	case mac:
+		r.ShortName = `MAC`
+	case d.tvReg.IsMatchUserAgent(ua):
+		r.ShortName = `ATV`
+	default:
+		r.ShortName = `IOS`
+	}
+	r.Name = OperatingSystems[r.ShortName]
+	if mac && strings.Contains(ua, "x86_64") {
+		r.Platform = PlatformTypeX64
+	} else if mac && strings.Contains(ua, "arm64") {
+		r.Platform = PlatformTypeARM
+	}
+	return r
+}
+
+func (d *DarwinOs) darwinVersion(major, minor string, mac bool) string {
+	for _, key := range []string{major + "." + minor, major} {
+		if v, ok := d.darwin[key]; ok {
+			if mac && v.Mac != "" {
+				return v.Mac
+			}
+			if !mac && v.Ios != "" {
+				return v.Ios
+			}
+		}
+	}
+	return ""
+}
+
+func (d *DarwinOs) cfNetworkVersion(build string, mac bool) string {
+	n, err := strconv.Atoi(build)
+	if err != nil {
+		return ""
+	}
+	for _, v := range d.cfNetwork {
+		if n >= v.From && n <= v.To {
+			if mac {
+				return v.Mac
+			}
+			return v.Ios
+		}
+	}
+	return ""
+}
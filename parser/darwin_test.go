@@ -0,0 +1,42 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDarwinOsParse(t *testing.T) {
+	type OsFixture struct {
+		OsMatchResult `yaml:"os" json:"os"`
+		UserAgent     string `yaml:"user_agent" json:"user_agent"`
+	}
+
+	darwinParser, err := NewDarwinOs(nil, filepath.Join(dir, FixtureFileDarwin))
+	require.NoError(t, err)
+
+	var list []OsFixture
+	err = ReadYamlFile(`fixtures/darwin.yml`, &list)
+	if err != nil {
+		t.Error(err)
+	}
+
+	for _, item := range list {
+		r := darwinParser.Parse(item.UserAgent)
+		require.EqualValues(t, &item.OsMatchResult, r, item.UserAgent)
+	}
+
+	// left to the oss.yml rules
+	for _, ua := range []string{
+		`Instacast/2.0 CFNetwork/609.1.4 Darwin/13.0.0`,
+		`MyApp/1.0 CFNetwork/808.3 Darwin/16.3.0`,
+		`Mozilla/5.0 (iPhone; CPU iPhone OS 16_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.1 Mobile/15E148 Safari/604.1`,
+	} {
+		require.Nil(t, darwinParser.Parse(ua), ua)
+	}
+
+	SetVersionTruncation(VERSION_TRUNCATION_MAJOR)
+	defer ResetParserAbstract()
+	require.Equal(t, "16", darwinParser.Parse(`MyApp/3.2 CFNetwork/1408.0.4 Darwin/22.5.0`).Version)
+}
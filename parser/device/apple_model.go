@@ -0,0 +1,120 @@
+package device
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/gianluca-marchini/devicedetector/parser"
+)
+
+const ParserNameAppleModel = `apple model`
+const FixtureFileAppleModel = `apple_models.yml`
+
+func init() {
+	RegDeviceParser(ParserNameAppleModel,
+		func(fsys fs.FS, dir string) DeviceParser {
+			p, err := NewAppleModel(fsys, filepath.Join(dir, FixtureFileAppleModel))
+			if err != nil {
+				return nil
+			}
+			return p
+		})
+}
+
+type AppleHardware struct {
+	Model  string `yaml:"model" json:"model"`
+	Device string `yaml:"device" json:"device"`
+}
+
+// Device parser resolving the Apple hardware identifiers sent by the apps and
+// SDKs (iPhone14,2, iPad13,4, Watch6,1...) to the marketing model and the
+// device type
+type AppleModel struct {
+	models map[string]*AppleHardware
+	file   string
+	reg    parser.Regular
+}
+
+// Families of the hardware identifiers, by lower case prefix
+var appleFamilies = map[string]string{
+	`iphone`:         `iPhone`,
+	`iph`:            `iPhone`,
+	`ipad`:           `iPad`,
+	`ipod`:           `iPod`,
+	`watch`:          `Watch`,
+	`appletv`:        `AppleTV`,
+	`audioaccessory`: `AudioAccessory`,
+}
+
+func NewAppleModel(fsys fs.FS, file string) (*AppleModel, error) {
+	p := &AppleModel{
+		models: make(map[string]*AppleHardware),
+		file:   file,
+		reg:    parser.Regular{Regex: `(?:Apple-)?(iPhone|iPh|iPad|iPod|Watch|AppleTV|AudioAccessory)(\d+)[C,_](\d+)`},
+	}
+	p.reg.Compile()
+	if err := readAppleModels(fsys, file, p.models); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func readAppleModels(fsys fs.FS, file string, into map[string]*AppleHardware) error {
+	var v map[string]*AppleHardware
+	if err := parser.ReadYamlFS(fsys, file, &v); err != nil {
+		return err
+	}
+	for id, hw := range v {
+		if hw == nil || hw.Model == "" {
+			return fmt.Errorf("%s: empty model for %q", file, id)
+		}
+		if parser.GetDeviceType(hw.Device) == parser.DEVICE_TYPE_INVALID {
+			return fmt.Errorf("%s: unknown device type %q for %q", file, hw.Device, id)
+		}
+		into[id] = hw
+	}
+	return nil
+}
+
+// Merges the identifiers of the overlay file found in dir over the loaded
+// ones
+func (p *AppleModel) ApplyOverlay(dir string) error {
+	file, ok := parser.OverlayFile(dir, p.file)
+	if !ok {
+		return nil
+	}
+	models := make(map[string]*AppleHardware)
+	if err := readAppleModels(nil, file, models); err != nil {
+		return err
+	}
+	for id, hw := range models {
+		p.models[id] = hw
+	}
+	return nil
+}
+
+func (p *AppleModel) PreMatch(ua string) bool {
+	return p.reg.IsMatchUserAgent(ua)
+}
+
+// Returns the model of the first hardware identifier of ua found in the
+// table, keeping the identifier in RawModel, nil for the unknown identifiers
+func (p *AppleModel) Parse(ua string) *DeviceMatchResult {
+	m := p.reg.MatchUserAgent(ua)
+	if len(m) != 4 {
+		return nil
+	}
+	id := appleFamilies[strings.ToLower(m[1])] + m[2] + "," + m[3]
+	hw, ok := p.models[id]
+	if !ok {
+		return nil
+	}
+	return &DeviceMatchResult{
+		Type:     hw.Device,
+		Model:    hw.Model,
+		RawModel: id,
+		Brand:    `AP`,
+	}
+}
@@ -0,0 +1,30 @@
+package device
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gianluca-marchini/devicedetector/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppleModelParse(t *testing.T) {
+	ps, err := NewAppleModel(nil, filepath.Join(dir, FixtureFileAppleModel))
+	require.NoError(t, err)
+	var list []*DeviceFixture
+	err = parser.ReadYamlFile(`fixtures/apple_model.yml`, &list)
+	if err != nil {
+		t.Error(err)
+	}
+
+	for _, item := range list {
+		ua := item.UserAgent
+		r := ps.Parse(ua)
+		test := item.GetDeviceMatchResult()
+		require.EqualValues(t, test, r, ua)
+	}
+
+	// identifiers missing from the table are left to the other parsers
+	require.Nil(t, ps.Parse(`MyApp/1.0 (iPhone99,1; iOS 30.0)`))
+	require.Nil(t, ps.Parse(`Mozilla/5.0 (iPhone; CPU iPhone OS 16_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.1 Mobile/15E148 Safari/604.1`))
+}
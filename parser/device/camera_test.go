@@ -9,7 +9,7 @@ import (
 )
 
 func TestCameraParse(t *testing.T) {
-	ps := NewCamera(filepath.Join(dir, FixtureFileCamera))
+	ps := NewCamera(nil, filepath.Join(dir, FixtureFileCamera))
 	var list []*DeviceFixture
 	err := parser.ReadYamlFile(`fixtures/camera.yml`, &list)
 	if err != nil {
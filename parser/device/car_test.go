@@ -9,7 +9,7 @@ import (
 )
 
 func TestCarParse(t *testing.T) {
-	ps := NewCar(filepath.Join(dir, FixtureFileCar))
+	ps := NewCar(nil, filepath.Join(dir, FixtureFileCar))
 	var list []*DeviceFixture
 	err := parser.ReadYamlFile(`fixtures/car_browser.yml`, &list)
 	if err != nil {
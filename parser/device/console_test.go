@@ -9,7 +9,7 @@ import (
 )
 
 func TestConsoleParse(t *testing.T) {
-	ps := NewConsole(filepath.Join(dir, FixtureFileConsole))
+	ps := NewConsole(nil, filepath.Join(dir, FixtureFileConsole))
 	var list []*DeviceFixture
 	err := parser.ReadYamlFile(`fixtures/console.yml`, &list)
 	if err != nil {
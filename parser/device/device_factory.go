@@ -1,12 +1,14 @@
 package device
 
-var deviceFactory = make(map[string]func(string) DeviceParser, 10)
+import "io/fs"
 
-func RegDeviceParser(name string, f func(string) DeviceParser) {
+var deviceFactory = make(map[string]func(fs.FS, string) DeviceParser, 10)
+
+func RegDeviceParser(name string, f func(fs.FS, string) DeviceParser) {
 	deviceFactory[name] = f
 }
 
-func GetDeviceCreater(name string) func(string) DeviceParser {
+func GetDeviceCreater(name string) func(fs.FS, string) DeviceParser {
 	f, exists := deviceFactory[name]
 	if !exists {
 		return nil
@@ -14,18 +16,18 @@ func GetDeviceCreater(name string) func(string) DeviceParser {
 	return f
 }
 
-func NewDeviceParser(dir, name string) DeviceParser {
+func NewDeviceParser(fsys fs.FS, dir, name string) DeviceParser {
 	if f, ok := deviceFactory[name]; ok {
-		return f(dir)
+		return f(fsys, dir)
 	}
 	return nil
 }
 
-func NewDeviceParsers(dir string, names []string) []DeviceParser {
+func NewDeviceParsers(fsys fs.FS, dir string, names []string) []DeviceParser {
 	r := make([]DeviceParser, len(names))
 	for i, name := range names {
 		if f, ok := deviceFactory[name]; ok {
-			r[i] = f(dir)
+			r[i] = f(fsys, dir)
 		}
 	}
 	return r
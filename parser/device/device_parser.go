@@ -1,9 +1,13 @@
 package device
 
 import (
+	"fmt"
+	"io/fs"
 	"sort"
 	"strings"
 
+	"gopkg.in/yaml.v2"
+
 	"github.com/gianluca-marchini/devicedetector/parser"
 )
 
@@ -13,6 +17,9 @@ type DeviceMatchResult struct {
 	Type  string `yaml:"type"`
 	Model string `yaml:"model"`
 	Brand string `yaml:"brand"`
+	// Model code reported by the device parser when Model has been renamed
+	// to its marketing name by the model aliases
+	RawModel string `yaml:"raw_model,omitempty"`
 }
 
 type DeviceParser interface {
@@ -32,16 +39,41 @@ type DeviceReg struct {
 	Model          string   `yaml:"model" json:"model"`
 	Device         string   `yaml:"device" json:"device"`
 	Models         []*Model `yaml:"models" json:"models"`
+	Disabled       bool     `yaml:"disabled" json:"disabled"`
 }
 
 type DeviceParserAbstract struct {
-	Regexes      map[string]*DeviceReg
+	Regexes map[string]*DeviceReg
+	// overlay and user-defined entries by brand, tried in order before the
+	// loaded one
+	extraRegs    map[string][]*DeviceReg
+	brands       []string
+	file         string
 	overAllMatch parser.Regular
 }
 
-func (d *DeviceParserAbstract) Load(file string) error {
+// Reads a device regexes file, returning the brands in the order they are
+// declared: the first matching brand wins, as in the original library.
+func readDeviceRegs(fsys fs.FS, file string) (map[string]*DeviceReg, []string, error) {
 	var v map[string]*DeviceReg
-	err := parser.ReadYamlFile(file, &v)
+	if err := parser.ReadYamlFS(fsys, file, &v); err != nil {
+		return nil, nil, err
+	}
+	var order yaml.MapSlice
+	if err := parser.ReadYamlFS(fsys, file, &order); err != nil {
+		return nil, nil, err
+	}
+	brands := make([]string, 0, len(order))
+	for _, item := range order {
+		if brand, ok := item.Key.(string); ok {
+			brands = append(brands, brand)
+		}
+	}
+	return v, brands, nil
+}
+
+func (d *DeviceParserAbstract) Load(fsys fs.FS, file string) error {
+	v, brands, err := readDeviceRegs(fsys, file)
 	if err != nil {
 		return err
 	}
@@ -52,27 +84,142 @@ func (d *DeviceParserAbstract) Load(file string) error {
 		}
 	}
 	d.Regexes = v
+	d.extraRegs = nil
+	d.brands = brands
+	d.file = file
+	return nil
+}
+
+// Checks a brand entry not shipped with the library
+func validateDeviceReg(brand string, item *DeviceReg) error {
+	if brand != UnknownBrand && parser.FindBrand(brand) == "" {
+		return fmt.Errorf("unknown brand %q", brand)
+	}
+	if item.Device != "" && parser.GetDeviceType(item.Device) == parser.DEVICE_TYPE_INVALID {
+		return fmt.Errorf("%s: unknown device type %q", brand, item.Device)
+	}
+	if err := item.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", brand, err)
+	}
+	for _, m := range item.Models {
+		if m.Device != "" && parser.GetDeviceType(m.Device) == parser.DEVICE_TYPE_INVALID {
+			return fmt.Errorf("%s: unknown device type %q", brand, m.Device)
+		}
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", brand, err)
+		}
+	}
+	return nil
+}
+
+// Entries of brand in the order they are tried
+func (d *DeviceParserAbstract) brandRegs(brand string) []*DeviceReg {
+	regs := d.extraRegs[brand]
+	if item, ok := d.Regexes[brand]; ok {
+		return append(regs[:len(regs):len(regs)], item)
+	}
+	return regs
+}
+
+// Merges the overlay file found in dir ahead of the loaded brands.
+// The entries of a brand already known are tried before its loaded entries,
+// which are kept as they are; disabled brands are removed. The whole file
+// is checked before any change is made.
+func (d *DeviceParserAbstract) ApplyOverlay(dir string) error {
+	file, ok := parser.OverlayFile(dir, d.file)
+	if !ok {
+		return nil
+	}
+	v, brands, err := readDeviceRegs(nil, file)
+	if err != nil {
+		return err
+	}
+	for _, brand := range brands {
+		if item := v[brand]; item != nil && !item.Disabled {
+			if err := validateDeviceReg(brand, item); err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+		}
+	}
+
+	regexes := make(map[string]*DeviceReg, len(d.Regexes))
+	for brand, item := range d.Regexes {
+		regexes[brand] = item
+	}
+	extraRegs := make(map[string][]*DeviceReg, len(d.extraRegs)+len(brands))
+	for brand, regs := range d.extraRegs {
+		extraRegs[brand] = regs
+	}
+	order := make([]string, 0, len(d.brands)+len(brands))
+	overlaid := make(map[string]bool, len(brands))
+	for _, brand := range brands {
+		item := v[brand]
+		if item == nil {
+			continue
+		}
+		overlaid[brand] = true
+		if item.Disabled {
+			delete(regexes, brand)
+			delete(extraRegs, brand)
+			continue
+		}
+		extraRegs[brand] = append([]*DeviceReg{item}, extraRegs[brand]...)
+		order = append(order, brand)
+	}
+	for _, brand := range d.brands {
+		if !overlaid[brand] {
+			order = append(order, brand)
+		}
+	}
+	d.Regexes = regexes
+	d.extraRegs = extraRegs
+	d.brands = order
+	d.overAllMatch = parser.Regular{}
+	return nil
+}
+
+// Adds a rule for brand ahead of the loaded ones. The device type and model
+// are those of the first matching model. When the brand is already known,
+// the rule is tried before its loaded entries, like an overlay entry.
+func (d *DeviceParserAbstract) AddRule(brand, regex string, models ...*Model) error {
+	item := &DeviceReg{
+		Regular: parser.Regular{Regex: regex},
+		Models:  models,
+	}
+	if err := validateDeviceReg(brand, item); err != nil {
+		return err
+	}
+	extraRegs := make(map[string][]*DeviceReg, len(d.extraRegs)+1)
+	for b, regs := range d.extraRegs {
+		extraRegs[b] = regs
+	}
+	extraRegs[brand] = append([]*DeviceReg{item}, extraRegs[brand]...)
+	brands := make([]string, 0, len(d.brands)+1)
+	brands = append(brands, brand)
+	for _, b := range d.brands {
+		if b != brand {
+			brands = append(brands, b)
+		}
+	}
+	d.extraRegs = extraRegs
+	d.brands = brands
+	d.overAllMatch = parser.Regular{}
 	return nil
 }
 
 func (d *DeviceParserAbstract) PreMatch(ua string) bool {
 	if d.overAllMatch.Regexp == nil {
-		count := len(d.Regexes)
-		if count == 0 {
-			return false
-		}
-		sortKeys := make([]string, 0, count)
-		for k := range d.Regexes {
-			sortKeys = append(sortKeys, k)
+		var regs []string
+		for _, brand := range d.brands {
+			for _, item := range d.brandRegs(brand) {
+				regs = append(regs, item.Regex)
+			}
 		}
-		sort.Strings(sortKeys)
-		sb := strings.Builder{}
-		sb.WriteString(d.Regexes[sortKeys[count-1]].Regex)
-		for i := count - 2; i >= 0; i-- {
-			sb.WriteString("|")
-			sb.WriteString(d.Regexes[sortKeys[i]].Regex)
+		if len(regs) == 0 {
+			return false
 		}
-		d.overAllMatch.Regex = sb.String()
+		sort.Strings(regs)
+		d.overAllMatch.Regex = strings.Join(regs, "|")
 		d.overAllMatch.Compile()
 	}
 	r := d.overAllMatch.IsMatchUserAgent(ua)
@@ -83,10 +230,13 @@ func (d *DeviceParserAbstract) Parse(ua string) *DeviceMatchResult {
 	var regex *DeviceReg
 	var brand string
 	var matches []string
-	for brand, regex = range d.Regexes {
-		matches = regex.MatchUserAgent(ua)
-		if len(matches) > 0 {
-			break
+brands:
+	for _, brand = range d.brands {
+		for _, regex = range d.brandRegs(brand) {
+			matches = regex.MatchUserAgent(ua)
+			if len(matches) > 0 {
+				break brands
+			}
 		}
 	}
 
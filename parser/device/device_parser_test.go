@@ -0,0 +1,53 @@
+package device
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeOverlay(t *testing.T, content string) string {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, FixtureFileMobile), []byte(content), 0o644))
+	return dir
+}
+
+func TestDeviceOverlayKeepsLoadedEntries(t *testing.T) {
+	ps := NewMobile(nil, filepath.Join(dir, FixtureFileMobile))
+	require.NoError(t, ps.ApplyOverlay(writeOverlay(t, `
+Vestel:
+  regex: 'AcmeScreen ([0-9]+)'
+  device: 'tv'
+  model: 'AcmeScreen $1'
+`)))
+
+	r := ps.Parse(`Mozilla/5.0 (Linux; Android 9; AcmeScreen 55) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/80.0.3987.87 Safari/537.36`)
+	require.Equal(t, &DeviceMatchResult{Type: `tv`, Brand: `VT`, Model: `AcmeScreen 55`}, r)
+
+	// the loaded Vestel entry keeps its device type and models
+	r = ps.Parse(`Mozilla/5.0 (Linux; Android 4.3; VSP145M Build/JLS36C) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/47.0.2526.83 Mobile Safari/537.36`)
+	require.Equal(t, `smartphone`, r.Type)
+	require.Equal(t, `VT`, r.Brand)
+	r = ps.Parse(`Mozilla/5.0 (Linux; Android 8.1.0; Venus GO) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/75.0.3770.143 Mobile Safari/537.36`)
+	require.Equal(t, `smartphone`, r.Type)
+	require.Equal(t, `Venus Go`, r.Model)
+}
+
+func TestDeviceOverlayInvalid(t *testing.T) {
+	ps := NewMobile(nil, filepath.Join(dir, FixtureFileMobile))
+	ua := `Mozilla/5.0 (Linux; Android 4.4.2; Nexus 4 Build/KOT49H) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/33.0.1750.136 Mobile Safari/537.36`
+	before := ps.Parse(ua)
+	require.NotNil(t, before)
+
+	// the disabled brand is declared before the invalid entry: nothing may
+	// change when the file is rejected
+	require.Error(t, ps.ApplyOverlay(writeOverlay(t, `
+Google:
+  disabled: true
+Vestel:
+  regex: 'Acme('
+`)))
+	require.Equal(t, before, ps.Parse(ua))
+}
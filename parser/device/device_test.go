@@ -7,9 +7,10 @@ import (
 const dir = "../../regexes/device"
 
 type DeviceFixtureResult struct {
-	Type  int    `yaml:"type"`
-	Model string `yaml:"model"`
-	Brand string `yaml:"brand"`
+	Type     int    `yaml:"type"`
+	Model    string `yaml:"model"`
+	RawModel string `yaml:"raw_model"`
+	Brand    string `yaml:"brand"`
 }
 
 type DeviceFixture struct {
@@ -19,8 +20,9 @@ type DeviceFixture struct {
 
 func (d *DeviceFixture) GetDeviceMatchResult() *DeviceMatchResult {
 	return &DeviceMatchResult{
-		Model: d.Model,
-		Brand: d.Brand,
-		Type:  parser.GetDeviceName(d.Type),
+		Model:    d.Model,
+		RawModel: d.RawModel,
+		Brand:    d.Brand,
+		Type:     parser.GetDeviceName(d.Type),
 	}
 }
@@ -0,0 +1,106 @@
+package device
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/gianluca-marchini/devicedetector/parser"
+)
+
+const FixtureFileModelAlias = `model_aliases.yml`
+
+type ModelAlias struct {
+	// matched against the whole model reported by the device parsers
+	parser.Regular `yaml:",inline" json:",inline"`
+	// marketing name, may reference the groups of the regex ($1...)
+	Name string `yaml:"name" json:"name"`
+}
+
+// Maps the model codes reported by the device parsers (SM-G991B, CPH2173...)
+// to the marketing names, brand by brand
+type ModelAliases struct {
+	aliases map[string][]*ModelAlias
+	file    string
+}
+
+func NewModelAliases(fsys fs.FS, file string) (*ModelAliases, error) {
+	aliases, err := readModelAliases(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+	return &ModelAliases{
+		aliases: aliases,
+		file:    file,
+	}, nil
+}
+
+// Reads an aliases file, keyed by the full brand names as in mobiles.yml,
+// returning the aliases by brand id
+func readModelAliases(fsys fs.FS, file string) (map[string][]*ModelAlias, error) {
+	var v map[string][]*ModelAlias
+	if err := parser.ReadYamlFS(fsys, file, &v); err != nil {
+		return nil, err
+	}
+	r := make(map[string][]*ModelAlias, len(v))
+	for brand, list := range v {
+		brandId := parser.FindBrand(brand)
+		if brandId == "" {
+			return nil, fmt.Errorf("%s: unknown brand %q", file, brand)
+		}
+		for _, alias := range list {
+			if alias.Name == "" {
+				return nil, fmt.Errorf("%s: %s: empty name for %q", file, brand, alias.Regex)
+			}
+			alias.Regex = `^(?:` + alias.Regex + `)$`
+			if err := alias.Validate(); err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", file, brand, err)
+			}
+		}
+		r[brandId] = list
+	}
+	return r, nil
+}
+
+// Merges the overlay file found in dir ahead of the loaded aliases
+func (a *ModelAliases) ApplyOverlay(dir string) error {
+	file, ok := parser.OverlayFile(dir, a.file)
+	if !ok {
+		return nil
+	}
+	aliases, err := readModelAliases(nil, file)
+	if err != nil {
+		return err
+	}
+	for brandId, list := range aliases {
+		a.aliases[brandId] = append(list, a.aliases[brandId]...)
+	}
+	return nil
+}
+
+// Returns the marketing name of model for the brand id, false when it has
+// no alias
+func (a *ModelAliases) Resolve(brand, model string) (string, bool) {
+	if model == "" {
+		return "", false
+	}
+	for _, alias := range a.aliases[brand] {
+		if m := alias.MatchUserAgent(model); len(m) > 0 {
+			if name := strings.TrimSpace(parser.BuildModel(alias.Name, m)); name != "" {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Renames the model of r to its marketing name, keeping the code in RawModel
+func (a *ModelAliases) Apply(r *DeviceMatchResult) {
+	if r == nil {
+		return
+	}
+	if name, ok := a.Resolve(r.Brand, r.Model); ok && name != r.Model {
+		r.RawModel = r.Model
+		r.Model = name
+	}
+}
@@ -0,0 +1,43 @@
+package device
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelAliases(t *testing.T) {
+	aliases, err := NewModelAliases(nil, filepath.Join(dir, FixtureFileModelAlias))
+	require.NoError(t, err)
+
+	data := []struct {
+		brand string
+		model string
+		name  string
+	}{
+		{`SA`, `SM-G991B`, `Galaxy S21 5G`},
+		{`SA`, `sm-g998u`, `Galaxy S21 Ultra 5G`},
+		{`XI`, `M2101K6G`, `Redmi Note 10 Pro`},
+		{`OP`, `CPH2173`, `Find X3 Pro`},
+		// the aliases are scoped by brand and match the whole model
+		{`XI`, `SM-G991B`, ``},
+		{`SA`, `SM-G991B Duos`, ``},
+		{`SA`, ``, ``},
+	}
+	for _, item := range data {
+		name, ok := aliases.Resolve(item.brand, item.model)
+		require.Equal(t, item.name != "", ok, item.model)
+		require.Equal(t, item.name, name, item.model)
+	}
+
+	r := &DeviceMatchResult{Type: `smartphone`, Brand: `SA`, Model: `SM-A536B`}
+	aliases.Apply(r)
+	require.Equal(t, `Galaxy A53 5G`, r.Model)
+	require.Equal(t, `SM-A536B`, r.RawModel)
+
+	r = &DeviceMatchResult{Type: `smartphone`, Brand: `SA`, Model: `Galaxy S8`}
+	aliases.Apply(r)
+	require.Equal(t, `Galaxy S8`, r.Model)
+	require.Empty(t, r.RawModel)
+}
@@ -0,0 +1,205 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const ParserNameIabBot = `iab`
+const FixtureFileIabSpiders = `iab_spiders.txt`
+const FixtureFileIabBrowsers = `iab_browsers.txt`
+
+// Category of the bots found by the IAB parser
+const BotCategoryIab = "IAB Spiders & Bots"
+
+// Name of the bots found by the IAB parser for matching none of the valid
+// browsers, the others being named after the spiders pattern
+const BotNameIabInvalidBrowser = "invalid browser"
+
+func init() {
+	RegBotParser(ParserNameIabBot,
+		func(fsys fs.FS, dir string) BotParser {
+			browsersFile := filepath.Join(dir, FixtureFileIabBrowsers)
+			if _, err := readIabData(fsys, browsersFile); errors.Is(err, fs.ErrNotExist) {
+				browsersFile = ""
+			}
+			p, err := NewIabBot(fsys, filepath.Join(dir, FixtureFileIabSpiders), browsersFile)
+			if err != nil {
+				return nil
+			}
+			return p
+		})
+}
+
+type iabPattern struct {
+	pattern       string
+	exceptions    []string
+	startOfString bool
+}
+
+func (p *iabPattern) matchUserAgent(ua string) bool {
+	if p.startOfString {
+		return strings.HasPrefix(ua, p.pattern)
+	}
+	return strings.Contains(ua, p.pattern)
+}
+
+// Bot parser applying the IAB/ABC International Spiders & Bots list. The
+// list is licensed, so it isn't bundled: load it from the files delivered
+// by the IAB, or copy them to iab_spiders.txt and iab_browsers.txt in the
+// regexes directory to select the parser by name.
+type IabBot struct {
+	spiders        []*iabPattern
+	browsers       []*iabPattern
+	discardDetails bool
+}
+
+// Load the IAB pattern files. Both are pipe-delimited, one pattern per line:
+//   - spidersFile: pattern|active flag (1 or 0)|exceptions, separated by
+//     commas|primary impact flag|start-of-string flag (1 or 0)
+//   - browsersFile, the valid browsers: pattern|active flag (1 or 0)|
+//     start-of-string flag (1 or 0)
+//
+// The lists are applied independently: a user agent matching a spiders
+// pattern but none of its exceptions is a bot, and so is a user agent
+// matching none of the valid browsers patterns. Patterns flagged
+// start-of-string must begin the user agent, the others may be found
+// anywhere in it. Blank lines, lines starting with # and the inactive
+// patterns are ignored. browsersFile is optional.
+func NewIabBot(fsys fs.FS, spidersFile, browsersFile string) (*IabBot, error) {
+	b := &IabBot{}
+	err := readIabFile(fsys, spidersFile, func(fields []string) {
+		p := &iabPattern{
+			pattern:       fields[0],
+			startOfString: iabFlag(fields, 4),
+		}
+		if len(fields) > 2 {
+			for _, e := range strings.Split(fields[2], ",") {
+				if e = strings.ToLower(strings.TrimSpace(e)); e != "" {
+					p.exceptions = append(p.exceptions, e)
+				}
+			}
+		}
+		b.spiders = append(b.spiders, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if browsersFile != "" {
+		err = readIabFile(fsys, browsersFile, func(fields []string) {
+			b.browsers = append(b.browsers, &iabPattern{
+				pattern:       fields[0],
+				startOfString: iabFlag(fields, 2),
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func iabFlag(fields []string, i int) bool {
+	return len(fields) > i && strings.TrimSpace(fields[i]) == "1"
+}
+
+// Read file from fsys, nil meaning the local disk
+func readIabData(fsys fs.FS, file string) ([]byte, error) {
+	if fsys != nil {
+		return fs.ReadFile(fsys, filepath.ToSlash(filepath.Clean(file)))
+	}
+	return os.ReadFile(file)
+}
+
+// Call f with the fields of the active patterns of file, the pattern
+// lower-cased
+func readIabFile(fsys fs.FS, file string, f func(fields []string)) error {
+	data, err := readIabData(fsys, file)
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "|")
+		if len(fields) < 2 {
+			return fmt.Errorf("%s:%d: missing active flag", file, n)
+		}
+		switch strings.TrimSpace(fields[1]) {
+		case "1":
+		case "0":
+			continue
+		default:
+			return fmt.Errorf("%s:%d: invalid active flag %q", file, n, fields[1])
+		}
+		fields[0] = strings.ToLower(strings.TrimSpace(fields[0]))
+		if fields[0] == "" {
+			continue
+		}
+		f(fields)
+	}
+	return scanner.Err()
+}
+
+func (b *IabBot) DiscardDetails(v bool) {
+	b.discardDetails = v
+}
+
+func (b *IabBot) PreMatch(ua string) bool {
+	return b.match(ua) != ""
+}
+
+// Returns the spiders pattern found in ua, or BotNameIabInvalidBrowser when
+// ua matches none of the valid browsers
+func (b *IabBot) match(ua string) string {
+	ua = strings.ToLower(ua)
+	for _, p := range b.spiders {
+		if !p.matchUserAgent(ua) {
+			continue
+		}
+		excepted := false
+		for _, e := range p.exceptions {
+			if strings.Contains(ua, e) {
+				excepted = true
+				break
+			}
+		}
+		if !excepted {
+			return p.pattern
+		}
+	}
+	if len(b.browsers) == 0 {
+		return ""
+	}
+	for _, p := range b.browsers {
+		if p.matchUserAgent(ua) {
+			return ""
+		}
+	}
+	return BotNameIabInvalidBrowser
+}
+
+// Parses the ua against the IAB patterns. The name of the result is the
+// matching spiders pattern, or BotNameIabInvalidBrowser.
+func (b *IabBot) Parse(ua string) *BotMatchResult {
+	pattern := b.match(ua)
+	if pattern == "" {
+		return nil
+	}
+	if b.discardDetails {
+		return EmptyBotMatchResult
+	}
+	return &BotMatchResult{
+		Name:     pattern,
+		Category: BotCategoryIab,
+	}
+}
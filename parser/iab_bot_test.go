@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIabBot(t *testing.T) {
+	iab, err := NewIabBot(nil, `fixtures/iab_spiders.txt`, `fixtures/iab_browsers.txt`)
+	require.NoError(t, err)
+
+	r := iab.Parse(`AcmeBot/1.0 (+http://acme.example/bot.html)`)
+	require.Equal(t, &BotMatchResult{Name: "bot", Category: BotCategoryIab}, r)
+	require.True(t, iab.PreMatch(`Java/1.8.0_151`))
+	// the spiders are flagged whatever the valid browsers say
+	require.Equal(t, "bot", iab.Parse(`Mozilla/5.0 (compatible; AcmeBot/1.0)`).Name)
+	require.Equal(t, "crawler", iab.Parse(`SiteCrawler/1.0 Opera/9.80`).Name)
+	// and so are the user agents matching none of the valid browsers
+	require.Equal(t, BotNameIabInvalidBrowser, iab.Parse(`curl/8.4.0`).Name)
+	require.Equal(t, BotNameIabInvalidBrowser, iab.Parse(`Lynx/2.8.9rel.1`).Name)
+	// start-of-string valid browsers must begin the user agent
+	require.Equal(t, BotNameIabInvalidBrowser, iab.Parse(`Links (compatible; Mozilla/5.0)`).Name)
+
+	// exceptions of the pattern and inactive patterns
+	require.Nil(t, iab.Parse(`Mozilla/5.0 (Linux; Android 10; CUBOT X19) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36`))
+	require.Nil(t, iab.Parse(`Mozilla/5.0 OldFetch/1.0`))
+	// start-of-string patterns
+	require.Nil(t, iab.Parse(`Mozilla/5.0 (compatible; Java/1.8.0_151)`))
+	require.Nil(t, iab.Parse(`Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36`))
+
+	iab.DiscardDetails(true)
+	require.Equal(t, EmptyBotMatchResult, iab.Parse(`AcmeBot`))
+
+	// without valid browsers, only the spiders are flagged
+	iab, err = NewIabBot(nil, `fixtures/iab_spiders.txt`, "")
+	require.NoError(t, err)
+	require.Equal(t, "bot", iab.Parse(`Mozilla/5.0 (compatible; AcmeBot/1.0)`).Name)
+	require.Nil(t, iab.Parse(`curl/8.4.0`))
+	_, err = NewIabBot(nil, `fixtures/oss.yml`, "")
+	require.Error(t, err)
+}
+
+func TestIabBotFactory(t *testing.T) {
+	p := NewBotParser(nil, `fixtures`, ParserNameIabBot)
+	require.NotNil(t, p)
+	require.Equal(t, "bot", p.Parse(`Mozilla/5.0 (compatible; AcmeBot/1.0)`).Name)
+	require.Equal(t, BotNameIabInvalidBrowser, p.Parse(`curl/8.4.0`).Name)
+	require.Nil(t, p.Parse(`Mozilla/5.0 (X11; Linux x86_64; rv:120.0) Gecko/20100101 Firefox/120.0`))
+
+	require.Nil(t, NewBotParser(nil, `.`, ParserNameIabBot))
+}
@@ -1,6 +1,9 @@
 package parser
 
 import (
+	"errors"
+	"io/fs"
+	"path/filepath"
 	"strings"
 )
 
@@ -8,9 +11,10 @@ const ParserNameOs = "os"
 const FixtureFileOs = "oss.yml"
 
 type OsReg struct {
-	Regular `yaml:",inline" json:",inline"`
-	Name    string `yaml:"name" json:"name"`
-	Version string `yaml:"version" json:"version"`
+	Regular  `yaml:",inline" json:",inline"`
+	Name     string `yaml:"name" json:"name"`
+	Version  string `yaml:"version" json:"version"`
+	Disabled bool   `yaml:"disabled" json:"disabled"`
 }
 
 // Known operating systems mapped to their internal short codes
@@ -152,14 +156,18 @@ type OsParser interface {
 
 // Parses the useragent for operating system information
 type Oss struct {
-	Regexes      []*OsReg
-	platforms    []*PlatformReg
+	Regexes   []*OsReg
+	platforms []*PlatformReg
+	file      string
+	// iOS versions of the Mobile/ build tokens, for the useragents telling
+	// no version. nil without ios_builds.yml, which is optional
+	iosBuilds    *BuildVersions
 	overAllMatch Regular
 }
 
-func NewOss(file string) (*Oss, error) {
+func NewOss(fsys fs.FS, file string) (*Oss, error) {
 	var v []*OsReg
-	err := ReadYamlFile(file, &v)
+	err := ReadYamlFS(fsys, file, &v)
 	if err != nil {
 		return nil, err
 	}
@@ -171,12 +179,49 @@ func NewOss(file string) (*Oss, error) {
 	for _, pp := range ps {
 		pp.Compile()
 	}
+	iosBuilds, err := NewBuildVersions(fsys, filepath.Join(filepath.Dir(file), FixtureFileIosBuild), `Mobile/(\d+[A-Z]\d+)`)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
 	return &Oss{
 		Regexes:   v,
 		platforms: ps,
+		file:      file,
+		iosBuilds: iosBuilds,
 	}, nil
 }
 
+func (o *Oss) ApplyOverlay(dir string) error {
+	regexes, err := MergeOverlay(dir, o.file, o.Regexes,
+		func(item *OsReg) (string, bool) { return item.Name, item.Disabled },
+		(*OsReg).Validate)
+	if err != nil {
+		return err
+	}
+	o.Regexes = regexes
+	o.overAllMatch = Regular{}
+	return nil
+}
+
+// Adds a rule ahead of the loaded ones.
+// name and version may reference the regex groups ($1, $2...).
+func (o *Oss) AddRule(regex, name, version string) error {
+	if name == "" {
+		return errors.New("empty os name")
+	}
+	item := &OsReg{
+		Regular: Regular{Regex: regex},
+		Name:    name,
+		Version: version,
+	}
+	if err := item.Validate(); err != nil {
+		return err
+	}
+	o.Regexes = append([]*OsReg{item}, o.Regexes...)
+	o.overAllMatch = Regular{}
+	return nil
+}
+
 func (o *Oss) ParsePlatform(ua string) string {
 	for i := 0; i < len(o.platforms); i++ {
 		p := o.platforms[i]
@@ -238,6 +283,9 @@ func (o *Oss) Parse(ua string) *OsMatchResult {
 		Version:   BuildVersion(osRegex.Version, matches),
 		Platform:  o.ParsePlatform(ua),
 	}
+	if result.Version == "" && short == `IOS` && o.iosBuilds != nil {
+		result.Version = o.iosBuilds.Parse(ua)
+	}
 	return result
 }
 
@@ -2,7 +2,10 @@ package parser
 
 import (
 	"errors"
+	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -37,12 +40,19 @@ const VERSION_TRUNCATION_BUILD = 3
 // Versioning constant used to set versioning to unlimited (no truncation)
 const VERSION_TRUNCATION_NONE = -1
 
-func SetVersionTruncation(t int) {
-	if t == VERSION_TRUNCATION_BUILD ||
+// Whether t is one of the VERSION_TRUNCATION constants
+func IsVersionTruncation(t int) bool {
+	return t == VERSION_TRUNCATION_BUILD ||
 		t == VERSION_TRUNCATION_NONE ||
 		t == VERSION_TRUNCATION_MAJOR ||
 		t == VERSION_TRUNCATION_MINOR ||
-		t == VERSION_TRUNCATION_PATCH {
+		t == VERSION_TRUNCATION_PATCH
+}
+
+// Sets the version truncation of every parser of the process. A single
+// detector can be truncated with DeviceDetector.SetVersionTruncation.
+func SetVersionTruncation(t int) {
+	if IsVersionTruncation(t) {
 		maxMinorParts = t
 	}
 }
@@ -60,14 +70,79 @@ func ArrayContainsString(list []string, v string) bool {
 	return false
 }
 
-func ReadYamlFile(file string, v interface{}) error {
-	data, err := os.ReadFile(file)
+// Read the yaml file into v. The files shipped with the library are read
+// from fsys, nil meaning the local disk, the overlays from the local disk.
+func ReadYamlFS(fsys fs.FS, file string, v interface{}) error {
+	var data []byte
+	var err error
+	if fsys != nil {
+		data, err = fs.ReadFile(fsys, filepath.ToSlash(filepath.Clean(file)))
+	} else {
+		data, err = os.ReadFile(file)
+	}
 	if err != nil {
-		return errors.New("not exists:" + file)
+		// keeps fs.ErrNotExist for the optional files
+		return fmt.Errorf("not exists:%s: %w", file, err)
 	}
 	return yaml.Unmarshal(data, v)
 }
 
+func ReadYamlFile(file string, v interface{}) error {
+	return ReadYamlFS(nil, file, v)
+}
+
+// Parsers whose rules can be extended by user-defined overlay files
+// sharing the schema of the bundled regexes.
+type Overlayer interface {
+	// Merges the rules of the overlay file found in dir ahead of the loaded
+	// ones. Overlay entries flagged as disabled remove the loaded entries
+	// with the same name.
+	ApplyOverlay(dir string) error
+}
+
+// Returns the path of the overlay counterpart of a regexes file, that is the
+// file with the same name inside dir, and whether it exists.
+func OverlayFile(dir, file string) (string, bool) {
+	overlay := filepath.Join(dir, filepath.Base(file))
+	if _, err := os.Stat(overlay); err != nil {
+		return overlay, false
+	}
+	return overlay, true
+}
+
+// Merges the entries of the overlay counterpart of file found in dir ahead
+// of loaded. Overlay entries flagged disabled, as told by key, remove the
+// loaded entries with the same name, the others must pass validate. Returns
+// loaded when dir has no overlay file.
+func MergeOverlay[T any](dir, file string, loaded []T, key func(T) (name string, disabled bool), validate func(T) error) ([]T, error) {
+	overlay, ok := OverlayFile(dir, file)
+	if !ok {
+		return loaded, nil
+	}
+	var v []T
+	if err := ReadYamlFile(overlay, &v); err != nil {
+		return nil, err
+	}
+	disabled := make(map[string]bool)
+	merged := make([]T, 0, len(v)+len(loaded))
+	for _, item := range v {
+		if name, off := key(item); off {
+			disabled[name] = true
+			continue
+		}
+		if err := validate(item); err != nil {
+			return nil, fmt.Errorf("%s: %w", overlay, err)
+		}
+		merged = append(merged, item)
+	}
+	for _, item := range loaded {
+		if name, _ := key(item); !disabled[name] {
+			merged = append(merged, item)
+		}
+	}
+	return merged, nil
+}
+
 type MatchResult interface {
 	GetName() string
 	SetName(string)
@@ -78,20 +153,40 @@ type Regular struct {
 	Regexp *regexp.Regexp
 }
 
+func (r *Regular) expression() string {
+	// $regex = '/(?:^|[^A-Z_-])(?:' . str_replace('/', '\/', $regex) . ')/i';
+	//str := `(?i)(?:^|[^A-Z0-9-_]|[^A-Z0-9-]_|sprd-)(?:` + r.Regex + ")"
+	rg := r.Regex
+	rg = strings.Replace(rg, `/`, `\/`, -1)
+	rg = strings.Replace(rg, `++`, `+`, -1)
+	rg = strings.Replace(rg, `\_`, `_`, -1)
+	return `(?:^|[^A-Z0-9-_]|[^A-Z0-9-]_|sprd-)(?:` + rg + ")"
+}
+
 func (r *Regular) Compile() *regexp.Regexp {
 	if r.Regexp == nil {
-		// $regex = '/(?:^|[^A-Z_-])(?:' . str_replace('/', '\/', $regex) . ')/i';
-		//str := `(?i)(?:^|[^A-Z0-9-_]|[^A-Z0-9-]_|sprd-)(?:` + r.Regex + ")"
-		rg := r.Regex
-		rg = strings.Replace(rg, `/`, `\/`, -1)
-		rg = strings.Replace(rg, `++`, `+`, -1)
-		rg = strings.Replace(rg, `\_`, `_`, -1)
-		str := `(?:^|[^A-Z0-9-_]|[^A-Z0-9-]_|sprd-)(?:` + rg + ")"
-		r.Regexp = regexp.MustCompile(str, regexp.IgnoreCase)
+		r.Regexp = regexp.MustCompile(r.expression(), regexp.IgnoreCase)
 	}
 	return r.Regexp
 }
 
+// Compiles the regex like Compile, returning an error instead of panicking
+// when the expression is invalid. Used for rules not shipped with the library.
+func (r *Regular) Validate() error {
+	if r.Regexp != nil {
+		return nil
+	}
+	if r.Regex == "" {
+		return errors.New("empty regex")
+	}
+	rx, err := regexp.Compile(r.expression(), regexp.IgnoreCase)
+	if err != nil {
+		return fmt.Errorf("invalid regex %q: %w", r.Regex, err)
+	}
+	r.Regexp = rx
+	return nil
+}
+
 func (r *Regular) IsMatchUserAgent(ua string) bool {
 	m, _ := r.Compile().MatchString(ua)
 	return m
@@ -135,14 +230,19 @@ func BuildByMatch(item string, matches []string) string {
 func BuildVersion(versionString string, matches []string) string {
 	ver := BuildByMatch(versionString, matches)
 	ver = strings.TrimRight(strings.ReplaceAll(ver, "_", "."), ".")
+	return TruncateVersion(ver, maxMinorParts)
+}
+
+// Keeps the parts of ver allowed by the truncation t, one of the
+// VERSION_TRUNCATION constants
+func TruncateVersion(ver string, t int) string {
 	verParts := strings.Split(ver, ".")
-	if maxMinorParts == -1 || len(verParts)-1 <= maxMinorParts {
+	if t < 0 || len(verParts)-1 <= t {
 		return ver
 	}
-	newVerParts := make([]string, 1+maxMinorParts)
+	newVerParts := make([]string, 1+t)
 	copy(newVerParts, verParts)
-	ver = strings.Join(newVerParts, ".")
-	return ver
+	return strings.Join(newVerParts, ".")
 }
 
 var (
@@ -11,7 +11,7 @@ import (
 const dir = "../regexes"
 
 func TestVendors(t *testing.T) {
-	v, err := NewVendor(filepath.Join(dir, FixtureFileVendor))
+	v, err := NewVendor(nil, filepath.Join(dir, FixtureFileVendor))
 	require.NoError(t, err)
 	str, _ := json.Marshal(v)
 	require.Equal(t, "{}", string(str))
@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"io/fs"
+	"strings"
+)
+
+const ParserNameScanner = "scanner"
+const FixtureFileScanner = "scanners.yml"
+
+// Threat categories of the scanners
+const (
+	ScannerCategoryInjection        = "injection"
+	ScannerCategoryBruteForce       = "brute force"
+	ScannerCategoryVulnerability    = "vulnerability scanner"
+	ScannerCategoryCms              = "cms scanner"
+	ScannerCategoryContentDiscovery = "content discovery"
+	ScannerCategoryFuzzer           = "fuzzer"
+	ScannerCategoryNetwork          = "network scanner"
+)
+
+// Severities of the scanners, from reconnaissance to takeover attempts
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+type ScannerReg struct {
+	Regular  `yaml:",inline" json:",inline"`
+	Name     string `yaml:"name" json:"name"`
+	Category string `yaml:"category" json:"category"`
+	Severity string `yaml:"severity" json:"severity"`
+	Version  string `yaml:"version" json:"version"`
+	Disabled bool   `yaml:"disabled" json:"disabled"`
+}
+
+type ScannerMatchResult struct {
+	Name     string `yaml:"name" json:"name"`
+	Category string `yaml:"category" json:"category"`
+	Severity string `yaml:"severity" json:"severity"`
+	Version  string `yaml:"version" json:"version"`
+}
+
+// Parses the useragent for attack tools and vulnerability scanners
+type Scanners struct {
+	Regexes      []*ScannerReg
+	file         string
+	overAllMatch Regular
+}
+
+func NewScanners(fsys fs.FS, file string) (*Scanners, error) {
+	var v []*ScannerReg
+	err := ReadYamlFS(fsys, file, &v)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range v {
+		item.Compile()
+	}
+	return &Scanners{
+		Regexes: v,
+		file:    file,
+	}, nil
+}
+
+func (s *Scanners) ApplyOverlay(dir string) error {
+	regexes, err := MergeOverlay(dir, s.file, s.Regexes,
+		func(item *ScannerReg) (string, bool) { return item.Name, item.Disabled },
+		(*ScannerReg).Validate)
+	if err != nil {
+		return err
+	}
+	s.Regexes = regexes
+	s.overAllMatch = Regular{}
+	return nil
+}
+
+// Matches ua against all the rules at once, without building the result
+func (s *Scanners) PreMatch(ua string) bool {
+	if s.overAllMatch.Regexp == nil {
+		count := len(s.Regexes)
+		if count == 0 {
+			return false
+		}
+		sb := strings.Builder{}
+		sb.WriteString(s.Regexes[count-1].Regex)
+		for i := count - 2; i >= 0; i-- {
+			sb.WriteString("|")
+			sb.WriteString(s.Regexes[i].Regex)
+		}
+		s.overAllMatch.Regex = sb.String()
+		s.overAllMatch.Compile()
+	}
+	return s.overAllMatch.IsMatchUserAgent(ua)
+}
+
+func (s *Scanners) Parse(ua string) *ScannerMatchResult {
+	if !s.PreMatch(ua) {
+		return nil
+	}
+	for _, regex := range s.Regexes {
+		matches := regex.MatchUserAgent(ua)
+		if len(matches) > 0 {
+			return &ScannerMatchResult{
+				Name:     BuildByMatch(regex.Name, matches),
+				Category: regex.Category,
+				Severity: regex.Severity,
+				Version:  BuildVersion(regex.Version, matches),
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScannerParse(t *testing.T) {
+	type ScannerFixture struct {
+		ScannerMatchResult `yaml:"scanner" json:"scanner"`
+		UserAgent          string `yaml:"user_agent" json:"user_agent"`
+	}
+
+	scannerParser, err := NewScanners(nil, filepath.Join(dir, FixtureFileScanner))
+	require.NoError(t, err)
+
+	var list []ScannerFixture
+	err = ReadYamlFile(`fixtures/scanners.yml`, &list)
+	if err != nil {
+		t.Error(err)
+	}
+
+	for _, item := range list {
+		r := scannerParser.Parse(item.UserAgent)
+		require.EqualValues(t, &item.ScannerMatchResult, r, item.UserAgent)
+	}
+
+	r := scannerParser.Parse(`Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36`)
+	require.Nil(t, r)
+}
@@ -12,7 +12,7 @@ func TestVendorParse(t *testing.T) {
 		Vendor    string `yaml:"vendor"`
 		UserAgent string `yaml:"useragent"`
 	}
-	var vendorParser, _ = NewVendor(filepath.Join(dir, FixtureFileVendor))
+	var vendorParser, _ = NewVendor(nil, filepath.Join(dir, FixtureFileVendor))
 	var list []VendorFixture
 	err := ReadYamlFile(`fixtures/vendorfragments.yml`, &list)
 	if err != nil {
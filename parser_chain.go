@@ -0,0 +1,97 @@
+package devicedetector
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Ordered list of named parsers (or rules), tried in order.
+type parserChain[T any] struct {
+	names   []string
+	parsers []T
+	added   int
+}
+
+func (c *parserChain[T]) index(name string) int {
+	for i, n := range c.names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *parserChain[T]) insert(at int, name string, p T) error {
+	if name == "" {
+		return fmt.Errorf("empty name")
+	}
+	if c.index(name) != -1 {
+		return fmt.Errorf("%q already registered", name)
+	}
+	c.names = append(c.names[:at], append([]string{name}, c.names[at:]...)...)
+	c.parsers = append(c.parsers[:at], append([]T{p}, c.parsers[at:]...)...)
+	return nil
+}
+
+// Appends p under an automatically generated name
+func (c *parserChain[T]) add(p T) {
+	for {
+		c.added++
+		name := fmt.Sprintf("custom-%d", c.added)
+		if c.index(name) == -1 {
+			c.names = append(c.names, name)
+			c.parsers = append(c.parsers, p)
+			return
+		}
+	}
+}
+
+func (c *parserChain[T]) insertBefore(ref, name string, p T) error {
+	i := c.index(ref)
+	if i == -1 {
+		return fmt.Errorf("%q not found", ref)
+	}
+	return c.insert(i, name, p)
+}
+
+func (c *parserChain[T]) insertAfter(ref, name string, p T) error {
+	i := c.index(ref)
+	if i == -1 {
+		return fmt.Errorf("%q not found", ref)
+	}
+	return c.insert(i+1, name, p)
+}
+
+func (c *parserChain[T]) replace(name string, p T) error {
+	i := c.index(name)
+	if i == -1 {
+		return fmt.Errorf("%q not found", name)
+	}
+	c.parsers[i] = p
+	return nil
+}
+
+func (c *parserChain[T]) remove(name string) error {
+	i := c.index(name)
+	if i == -1 {
+		return fmt.Errorf("%q not found", name)
+	}
+	c.names = append(c.names[:i], c.names[i+1:]...)
+	c.parsers = append(c.parsers[:i], c.parsers[i+1:]...)
+	return nil
+}
+
+func (c *parserChain[T]) list() []string {
+	names := make([]string, len(c.names))
+	copy(names, c.names)
+	return names
+}
+
+// Parser factories return typed nil pointers when the regexes can't be loaded
+func isNilParser(p interface{}) bool {
+	if p == nil {
+		return true
+	}
+	v := reflect.ValueOf(p)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
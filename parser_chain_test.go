@@ -0,0 +1,70 @@
+package devicedetector
+
+import (
+	"testing"
+
+	"github.com/gianluca-marchini/devicedetector/parser"
+	"github.com/gianluca-marchini/devicedetector/parser/client"
+	"github.com/gianluca-marchini/devicedetector/parser/device"
+	"github.com/stretchr/testify/require"
+)
+
+type staticClientParser struct {
+	result *client.ClientMatchResult
+}
+
+func (s *staticClientParser) PreMatch(string) bool {
+	return true
+}
+
+func (s *staticClientParser) Parse(string) *client.ClientMatchResult {
+	return s.result
+}
+
+func TestParserChain(t *testing.T) {
+	parser.ResetParserAbstract()
+
+	cd, err := NewDeviceDetector("regexes", false)
+	require.NoError(t, err)
+	require.Equal(t, DefaultClientParsers, cd.ClientParserNames())
+	require.Equal(t, DefaultDeviceParsers, cd.DeviceParserNames())
+	require.Equal(t, DefaultBotParsers, cd.BotParserNames())
+
+	ua := `Mozilla/5.0 (Linux; Android 4.4.2; Nexus 4 Build/KOT49H) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/33.0.1750.136 Mobile Safari/537.36`
+	app := &staticClientParser{&client.ClientMatchResult{Type: client.ParserNameMobileApp, Name: `Acme App`}}
+
+	// appended parsers are only tried after the built-in ones
+	cd.AddClientParser(app)
+	require.Equal(t, `custom-1`, cd.ClientParserNames()[len(DefaultClientParsers)])
+	require.Equal(t, `Chrome Mobile`, cd.Parse(ua).GetClient().Name)
+
+	require.NoError(t, cd.RemoveClientParser(`custom-1`))
+	require.NoError(t, cd.InsertClientParserBefore(client.ParserNameBrowser, `acme`, app))
+	require.Equal(t, `Acme App`, cd.Parse(ua).GetClient().Name)
+	require.Equal(t, []string{
+		client.ParserNameFeedReader,
+		client.ParserNameMobileApp,
+		client.ParserNameMediaPlayer,
+		client.ParserNamePim,
+		`acme`,
+		client.ParserNameBrowser,
+		client.ParserNameLibrary,
+		client.ParserNameDarwinApp,
+	}, cd.ClientParserNames())
+
+	require.NoError(t, cd.ReplaceClientParser(`acme`, &staticClientParser{}))
+	require.Equal(t, `Chrome Mobile`, cd.Parse(ua).GetClient().Name)
+
+	require.Error(t, cd.InsertClientParserAfter(`missing`, `other`, app))
+	require.Error(t, cd.InsertClientParserAfter(client.ParserNameLibrary, `acme`, app))
+	require.Error(t, cd.ReplaceClientParser(`missing`, app))
+
+	require.NoError(t, cd.RemoveDeviceParser(device.ParserNameMobile))
+	require.Equal(t, ``, cd.Parse(ua).GetBrandName())
+	require.NoError(t, cd.InsertDeviceParserAfter(device.ParserNamePortableMediaPlayer, device.ParserNameMobile,
+		device.NewMobile(nil, `regexes/device/`+device.FixtureFileMobile)))
+	require.Equal(t, `Google`, cd.Parse(ua).GetBrandName())
+
+	require.NoError(t, cd.RemoveBotParser(parser.ParserNameBot))
+	require.False(t, cd.Parse(`Googlebot/2.1 (http://www.googlebot.com/bot.html)`).IsBot())
+}
@@ -0,0 +1,10 @@
+// Package regexes embeds the bundled regexes, so that a detector can be
+// built without shipping the yml files alongside the binary.
+package regexes
+
+import "embed"
+
+// The bundled regexes, laid out as on disk: bots.yml, client/browsers.yml...
+//
+//go:embed *.yml client device
+var FS embed.FS
@@ -0,0 +1,271 @@
+// Package robots evaluates robots.txt files (RFC 9309) for the bots detected
+// by the device detector, so that the rules a bot is supposed to follow can
+// be enforced when it makes the request.
+package robots
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+
+	"github.com/gianluca-marchini/devicedetector"
+	"github.com/gianluca-marchini/devicedetector/parser"
+)
+
+// Crawlers are only required to parse the first 500 KiB of a robots.txt
+const MaxSize = 500 * 1024
+
+type rule struct {
+	allow   bool
+	pattern string
+}
+
+type group struct {
+	agents []string
+	rules  []rule
+}
+
+// Parsed robots.txt
+type Robots struct {
+	groups []*group
+}
+
+// Parse a robots.txt body. Lines that can't be parsed are ignored, as
+// required by the RFC.
+func Parse(body []byte) *Robots {
+	if len(body) > MaxSize {
+		body = body[:MaxSize]
+	}
+	r := &Robots{}
+	var current *group
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 4096), MaxSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "user-agent":
+			// user-agent lines following rules start a new group
+			if current == nil || len(current.rules) > 0 {
+				current = &group{}
+				r.groups = append(r.groups, current)
+			}
+			current.agents = append(current.agents, agentToken(value))
+		case "allow", "disallow":
+			if current == nil {
+				continue
+			}
+			current.rules = append(current.rules, rule{
+				allow:   strings.EqualFold(strings.TrimSpace(key), "allow"),
+				pattern: normalize(value),
+			})
+		}
+	}
+	return r
+}
+
+// Whether a crawler identifying with one of tokens may fetch path (with its
+// query string, if any). The groups of all the matching tokens are merged;
+// the * group applies only when none matches.
+func (r *Robots) Allowed(tokens []string, path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+	rules := r.rules(tokens)
+	path = normalize(path)
+
+	allowed, length := true, -1
+	for _, rl := range rules {
+		// empty disallow rules match nothing
+		if rl.pattern == "" {
+			continue
+		}
+		if !match(rl.pattern, path) {
+			continue
+		}
+		// the longest match wins, allow wins ties
+		if len(rl.pattern) > length || (len(rl.pattern) == length && rl.allow) {
+			allowed, length = rl.allow, len(rl.pattern)
+		}
+	}
+	return allowed
+}
+
+// Whether bot may fetch path
+func (r *Robots) AllowedBot(bot *parser.BotMatchResult, path string) bool {
+	return r.Allowed(Tokens(bot), path)
+}
+
+func (r *Robots) rules(tokens []string) []rule {
+	var rules, wildcard []rule
+	matched := false
+	for _, g := range r.groups {
+		// a group naming the bot applies to it even when it also lists *
+		wild, named := false, false
+		for _, agent := range g.agents {
+			if agent == "*" {
+				wild = true
+			} else if hasToken(tokens, agent) {
+				named = true
+				break
+			}
+		}
+		if named {
+			rules = append(rules, g.rules...)
+			matched = true
+		} else if wild {
+			wildcard = append(wildcard, g.rules...)
+		}
+	}
+	if !matched {
+		return wildcard
+	}
+	return rules
+}
+
+func hasToken(tokens []string, agent string) bool {
+	for _, token := range tokens {
+		if strings.EqualFold(token, agent) {
+			return true
+		}
+	}
+	return false
+}
+
+// Product tokens the bot obeys: its robots_tokens, or its name stripped of
+// the characters not allowed in a token when it has none
+func Tokens(bot *parser.BotMatchResult) []string {
+	if bot == nil {
+		return nil
+	}
+	if len(bot.RobotsTokens) > 0 {
+		return bot.RobotsTokens
+	}
+	if token := productToken(bot.Name, true); token != "" {
+		return []string{token}
+	}
+	return nil
+}
+
+// Whether the client described by info may fetch path according to the
+// robots.txt body. robots.txt only applies to bots, other clients are
+// always allowed.
+func Allowed(info *devicedetector.DeviceInfo, body []byte, path string) bool {
+	if info == nil || !info.IsBot() {
+		return true
+	}
+	return Parse(body).AllowedBot(info.GetBot(), path)
+}
+
+func agentToken(value string) string {
+	if strings.HasPrefix(value, "*") {
+		return "*"
+	}
+	return productToken(value, false)
+}
+
+// Characters of a product token: [a-zA-Z_-]. With skip, the other
+// characters are dropped, otherwise the token ends at the first of them.
+func productToken(value string, skip bool) string {
+	sb := strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '-' {
+			sb.WriteByte(c)
+		} else if !skip {
+			break
+		}
+	}
+	return sb.String()
+}
+
+const hexDigits = "0123456789ABCDEF"
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case c >= 'a':
+		return c - 'a' + 10
+	case c >= 'A':
+		return c - 'A' + 10
+	}
+	return c - '0'
+}
+
+func isUnreserved(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// Bring paths and patterns to a comparable form: non ASCII bytes are
+// percent-encoded, escapes of unreserved characters decoded and the other
+// escapes upper-cased
+func normalize(s string) string {
+	sb := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			d := unhex(s[i+1])<<4 | unhex(s[i+2])
+			if isUnreserved(d) {
+				sb.WriteByte(d)
+			} else {
+				sb.WriteByte('%')
+				sb.WriteByte(hexDigits[d>>4])
+				sb.WriteByte(hexDigits[d&15])
+			}
+			i += 2
+		case c >= 0x80:
+			sb.WriteByte('%')
+			sb.WriteByte(hexDigits[c>>4])
+			sb.WriteByte(hexDigits[c&15])
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// Match path against a rule pattern: patterns match path prefixes, * matches
+// any sequence of characters and a final $ anchors the end of the path
+func match(pattern, path string) bool {
+	if strings.HasSuffix(pattern, "$") {
+		pattern = pattern[:len(pattern)-1]
+	} else {
+		pattern += "*"
+	}
+	// glob matching, backtracking to the last * on mismatch
+	p, s, star, mark := 0, 0, -1, 0
+	for s < len(path) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, s
+			p++
+		case p < len(pattern) && pattern[p] == path[s]:
+			p++
+			s++
+		case star >= 0:
+			mark++
+			p, s = star+1, mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
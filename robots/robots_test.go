@@ -0,0 +1,135 @@
+package robots
+
+import (
+	"testing"
+
+	"github.com/gianluca-marchini/devicedetector"
+	"github.com/gianluca-marchini/devicedetector/parser"
+	"github.com/stretchr/testify/require"
+)
+
+var dd, _ = devicedetector.NewDeviceDetector("../regexes", false)
+
+const robotsTxt = `
+# comments are ignored
+User-agent: *
+Disallow: /private/
+Allow: /private/public.html
+Disallow: /*.pdf$
+Disallow:
+
+User-agent: GPTBot
+User-agent: CCBot
+Disallow: /
+
+user-agent: googlebot/2.1 # the version is not part of the token
+sitemap: https://example.com/sitemap.xml
+disallow: /search
+allow: /search/about
+
+User-Agent: Googlebot
+Disallow: /tmp
+Allow: /page
+Disallow: /page
+`
+
+func TestAllowed(t *testing.T) {
+	r := Parse([]byte(robotsTxt))
+	data := []struct {
+		tokens  []string
+		path    string
+		allowed bool
+	}{
+		{nil, "/", true},
+		{nil, "/private/", false},
+		{nil, "/private/x", false},
+		{nil, "/private/public.html", true},
+		{nil, "/doc.pdf", false},
+		{nil, "/doc.pdf?x=1", true},
+		{nil, "/robots.txt", true},
+		{[]string{"Foo"}, "/private/", false},
+		{[]string{"GPTBot"}, "/", false},
+		{[]string{"gptbot"}, "/robots.txt", true},
+		{[]string{"CCBot"}, "/private/public.html", false},
+		// groups of the same token are merged, the * group is ignored
+		{[]string{"Googlebot"}, "/private/", true},
+		{[]string{"Googlebot"}, "/search?q=1", false},
+		{[]string{"Googlebot"}, "/search/about", true},
+		{[]string{"Googlebot"}, "/tmp/x", false},
+		// allow wins ties
+		{[]string{"Googlebot"}, "/page", true},
+	}
+	for _, item := range data {
+		require.Equal(t, item.allowed, r.Allowed(item.tokens, item.path), "%v %s", item.tokens, item.path)
+	}
+}
+
+func TestSharedGroup(t *testing.T) {
+	// * listed before the bot in the same group
+	r := Parse([]byte(`
+User-agent: *
+User-agent: Googlebot
+Disallow: /private/
+
+User-agent: *
+Disallow: /
+`))
+	require.True(t, r.Allowed([]string{"Googlebot"}, "/page"))
+	require.False(t, r.Allowed([]string{"Googlebot"}, "/private/x"))
+	require.False(t, r.Allowed([]string{"Foo"}, "/page"))
+	require.False(t, r.Allowed([]string{"Foo"}, "/private/x"))
+}
+
+func TestMatch(t *testing.T) {
+	data := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"/fish", "/fish", true},
+		{"/fish", "/fish.html", true},
+		{"/fish", "/Fish.asp", false},
+		{"/fish*", "/fishheads/yummy.html", true},
+		{"/fish/", "/fish", false},
+		{"/*.php", "/folder/filename.php?parameters", true},
+		{"/*.php$", "/filename.php", true},
+		{"/*.php$", "/filename.php?parameters", false},
+		{"/fish*.php", "/fishheads/catfish.php?parameters", true},
+		{"/fish*.php", "/Fish.PHP", false},
+		{"/a*b*c$", "/aXbYbc", true},
+		{"/a*b*c$", "/aXbYbcd", false},
+	}
+	for _, item := range data {
+		require.Equal(t, item.match, match(item.pattern, item.path), "%s %s", item.pattern, item.path)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	r := Parse([]byte("User-agent: *\nDisallow: /%7ejoe/\nDisallow: /caf%c3%a9\nDisallow: /naïve\n"))
+	require.False(t, r.Allowed(nil, "/~joe/index.html"))
+	require.False(t, r.Allowed(nil, "/café"))
+	require.False(t, r.Allowed(nil, "/na%C3%AFve"))
+	require.True(t, r.Allowed(nil, "/cafe"))
+}
+
+func TestTokens(t *testing.T) {
+	require.Nil(t, Tokens(nil))
+	require.Equal(t, []string{"GPTBot"}, Tokens(&parser.BotMatchResult{Name: "GPTBot", RobotsTokens: []string{"GPTBot"}}))
+	require.Equal(t, []string{"SeznamBot"}, Tokens(&parser.BotMatchResult{Name: "Seznam Bot"}))
+	require.Nil(t, Tokens(&parser.BotMatchResult{}))
+}
+
+func TestAllowedDeviceInfo(t *testing.T) {
+	body := []byte("User-agent: ClaudeBot\nDisallow: /\n\nUser-agent: *\nDisallow: /admin\n")
+
+	info := dd.Parse(`Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; ClaudeBot/1.0; +claudebot@anthropic.com)`)
+	require.False(t, Allowed(info, body, "/articles/1"))
+
+	info = dd.Parse(`Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)`)
+	require.True(t, Allowed(info, body, "/articles/1"))
+	require.False(t, Allowed(info, body, "/admin"))
+
+	// robots.txt does not apply to browsers
+	info = dd.Parse(`Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36`)
+	require.True(t, Allowed(info, body, "/admin"))
+}
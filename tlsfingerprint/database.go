@@ -0,0 +1,128 @@
+// Package tlsfingerprint checks that the TLS fingerprint (JA3 or JA4) of a
+// connection is plausible for the client detected from its user agent, using
+// a local database mapping the fingerprints to the clients producing them.
+package tlsfingerprint
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gianluca-marchini/devicedetector"
+	"github.com/gianluca-marchini/devicedetector/parser/client"
+	"gopkg.in/yaml.v2"
+)
+
+type Verdict string
+
+const (
+	// The fingerprint is known to be produced by the detected client
+	VerdictPlausible Verdict = "plausible"
+	// The fingerprint is known, but only for other clients
+	VerdictMismatch Verdict = "mismatch"
+	// The fingerprint is not in the database, or there is no client to
+	// compare it to
+	VerdictUnknown Verdict = "unknown"
+)
+
+// Client producing a fingerprint. Empty fields match any client.
+type ClientMatcher struct {
+	// Client type: browser, library, mobile app...
+	Type string `yaml:"type"`
+	// Client name, e.g. Go-http-client
+	Name string `yaml:"name"`
+	// Browser family, e.g. Chrome for all the Chromium based browsers
+	Family string `yaml:"family"`
+}
+
+// TLS stack and the fingerprints it produces
+type Entry struct {
+	// Label of the TLS stack, e.g. Go net/http
+	Name         string          `yaml:"name"`
+	Fingerprints []string        `yaml:"fingerprints"`
+	Clients      []ClientMatcher `yaml:"clients"`
+}
+
+type Result struct {
+	Verdict Verdict
+	// TLS stacks known to produce the fingerprint
+	Stacks []string
+	// Explanation, e.g. claims Chrome 120, TLS looks like Go net/http
+	Reason string
+}
+
+type Database struct {
+	entries map[string][]*Entry
+}
+
+// Read the database from a YAML file holding a list of entries
+func LoadDatabase(file string) (*Database, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var entries []*Entry
+	if err := yaml.UnmarshalStrict(data, &entries); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return NewDatabase(entries), nil
+}
+
+func NewDatabase(entries []*Entry) *Database {
+	db := &Database{entries: make(map[string][]*Entry)}
+	for _, e := range entries {
+		for _, fp := range e.Fingerprints {
+			key := strings.ToLower(strings.TrimSpace(fp))
+			db.entries[key] = append(db.entries[key], e)
+		}
+	}
+	return db
+}
+
+func (m *ClientMatcher) match(c *client.ClientMatchResult) bool {
+	if m.Type != "" && !strings.EqualFold(m.Type, c.Type) {
+		return false
+	}
+	if m.Name != "" && !strings.EqualFold(m.Name, c.Name) {
+		return false
+	}
+	if m.Family != "" {
+		// the families are those of the browsers' short names
+		family := ""
+		if c.Type == client.ParserNameBrowser {
+			family, _ = client.GetBrowserFamily(c.ShortName)
+		}
+		if !strings.EqualFold(m.Family, family) {
+			return false
+		}
+	}
+	return true
+}
+
+// Check fingerprint against the client detected in info
+func (db *Database) Check(info *devicedetector.DeviceInfo, fingerprint string) Result {
+	entries := db.entries[strings.ToLower(strings.TrimSpace(fingerprint))]
+	if len(entries) == 0 {
+		return Result{Verdict: VerdictUnknown, Reason: "unknown fingerprint"}
+	}
+	stacks := make([]string, 0, len(entries))
+	for _, e := range entries {
+		stacks = append(stacks, e.Name)
+	}
+	if info == nil || info.GetClient().Name == "" {
+		return Result{Verdict: VerdictUnknown, Stacks: stacks, Reason: "no client detected"}
+	}
+	c := info.GetClient()
+	for _, e := range entries {
+		for i := range e.Clients {
+			if e.Clients[i].match(c) {
+				return Result{Verdict: VerdictPlausible, Stacks: stacks}
+			}
+		}
+	}
+	return Result{
+		Verdict: VerdictMismatch,
+		Stacks:  stacks,
+		Reason:  fmt.Sprintf("claims %s, TLS looks like %s", strings.TrimSpace(c.Name+" "+c.Version), strings.Join(stacks, " or ")),
+	}
+}
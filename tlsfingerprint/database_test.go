@@ -0,0 +1,48 @@
+package tlsfingerprint
+
+import (
+	"testing"
+
+	"github.com/gianluca-marchini/devicedetector"
+	"github.com/gianluca-marchini/devicedetector/parser/client"
+	"github.com/stretchr/testify/require"
+)
+
+var dd, _ = devicedetector.NewDeviceDetector("../regexes", false)
+
+const (
+	chrome     = `Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36`
+	vivaldi    = `Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Vivaldi/6.5.3206.48`
+	goClient   = `Go-http-client/1.1`
+	chromiumFp = `t13d0000h2_000000000001_000000000001`
+	goFp       = `t13d0000h2_000000000002_000000000002`
+)
+
+func TestCheck(t *testing.T) {
+	db, err := LoadDatabase("fixtures/fingerprints.yml")
+	require.NoError(t, err)
+
+	r := db.Check(dd.Parse(chrome), chromiumFp)
+	require.Equal(t, VerdictPlausible, r.Verdict)
+	require.Equal(t, []string{"Test Chromium"}, r.Stacks)
+
+	// matched through the browser family
+	require.Equal(t, VerdictPlausible, db.Check(dd.Parse(vivaldi), chromiumFp).Verdict)
+	// JA3 hashes work the same way, case insensitively
+	require.Equal(t, VerdictPlausible, db.Check(dd.Parse(chrome), "00000000000000000000000000000001").Verdict)
+
+	r = db.Check(dd.Parse(chrome), goFp)
+	require.Equal(t, VerdictMismatch, r.Verdict)
+	require.Equal(t, "claims Chrome 120.0.0.0, TLS looks like Test Go net/http or Test curl", r.Reason)
+
+	require.Equal(t, VerdictPlausible, db.Check(dd.Parse(goClient), goFp).Verdict)
+	require.Equal(t, VerdictMismatch, db.Check(dd.Parse(goClient), chromiumFp).Verdict)
+
+	require.Equal(t, VerdictUnknown, db.Check(dd.Parse(chrome), "t13d0000h2_ffffffffffff_ffffffffffff").Verdict)
+	require.Equal(t, VerdictUnknown, db.Check(nil, goFp).Verdict)
+
+	// the families are only those of the browsers
+	m := &ClientMatcher{Family: "Chrome"}
+	require.True(t, m.match(&client.ClientMatchResult{Type: client.ParserNameBrowser, ShortName: "CH"}))
+	require.False(t, m.match(&client.ClientMatchResult{Type: client.ParserNameMobileApp, ShortName: "CH"}))
+}